@@ -4,11 +4,15 @@ import (
 	"charging-stations-backend/internal/handlers"
 	"charging-stations-backend/internal/middleware"
 	"charging-stations-backend/internal/services"
+	"context"
 	"database/sql"
 	"fmt"
 	"log"
 	"os"
+	"strconv"
+	"strings"
 	"time"
+	_ "time/tzdata" // Europe/Istanbul, tzdata'sız imajlarda da yüklenebilsin
 
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
@@ -30,6 +34,65 @@ func createTables(db *sql.DB) error {
 
     CREATE INDEX IF NOT EXISTS idx_reviews_station_id ON reviews(station_id);
     CREATE INDEX IF NOT EXISTS idx_reviews_created_at ON reviews(created_at DESC);
+
+    CREATE TABLE IF NOT EXISTS station_availability (
+        id BIGSERIAL PRIMARY KEY,
+        station_id VARCHAR(255) NOT NULL,
+        ac_available INTEGER NOT NULL,
+        dc_available INTEGER NOT NULL,
+        error_count INTEGER NOT NULL,
+        unavailable_count INTEGER NOT NULL,
+        total_connectors INTEGER NOT NULL,
+        recorded_at TIMESTAMP WITH TIME ZONE NOT NULL
+    );
+
+    CREATE INDEX IF NOT EXISTS idx_station_availability_station_time ON station_availability(station_id, recorded_at);
+    CREATE INDEX IF NOT EXISTS idx_station_availability_recorded_at ON station_availability(recorded_at);
+
+    CREATE TABLE IF NOT EXISTS station_changes (
+        id BIGSERIAL PRIMARY KEY,
+        station_id VARCHAR(255) NOT NULL,
+        change_type VARCHAR(32) NOT NULL,
+        details JSONB NOT NULL,
+        detected_at TIMESTAMP WITH TIME ZONE NOT NULL
+    );
+
+    CREATE INDEX IF NOT EXISTS idx_station_changes_detected_at ON station_changes(detected_at);
+    CREATE INDEX IF NOT EXISTS idx_station_changes_station_id ON station_changes(station_id);
+
+    CREATE TABLE IF NOT EXISTS route_cache (
+        cache_key TEXT PRIMARY KEY,
+        payload JSONB NOT NULL,
+        expires_at TIMESTAMP WITH TIME ZONE NOT NULL
+    );
+
+    CREATE INDEX IF NOT EXISTS idx_route_cache_expires_at ON route_cache(expires_at);
+
+    CREATE TABLE IF NOT EXISTS webhook_subscriptions (
+        id SERIAL PRIMARY KEY,
+        url TEXT NOT NULL,
+        secret VARCHAR(128) NOT NULL,
+        event_types TEXT[] NOT NULL,
+        created_at TIMESTAMP WITH TIME ZONE NOT NULL
+    );
+
+    CREATE TABLE IF NOT EXISTS webhook_deliveries (
+        id BIGSERIAL PRIMARY KEY,
+        subscription_id INTEGER NOT NULL REFERENCES webhook_subscriptions(id) ON DELETE CASCADE,
+        event_type VARCHAR(64) NOT NULL,
+        payload JSONB NOT NULL,
+        status VARCHAR(16) NOT NULL,
+        attempts INTEGER NOT NULL DEFAULT 0,
+        last_status_code INTEGER,
+        last_error TEXT,
+        next_attempt_at TIMESTAMP WITH TIME ZONE,
+        created_at TIMESTAMP WITH TIME ZONE NOT NULL,
+        delivered_at TIMESTAMP WITH TIME ZONE
+    );
+
+    CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_due ON webhook_deliveries(next_attempt_at) WHERE status = 'pending';
+    CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_subscription ON webhook_deliveries(subscription_id, id DESC);
+    CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_dead ON webhook_deliveries(id DESC) WHERE status = 'dead';
     `
 
 	_, err := db.Exec(query)
@@ -41,17 +104,30 @@ func createTables(db *sql.DB) error {
 	return nil
 }
 
+// splitEnvList, virgülle ayrılmış bir ortam değişkenini boş olmayan öğelere ayırır
+func splitEnvList(key string) []string {
+	var items []string
+	for _, item := range strings.Split(os.Getenv(key), ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+func envOrDefault(key, fallback string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return fallback
+}
+
 func main() {
 	// .env dosyasını yükle
 	if err := godotenv.Load(); err != nil {
 		log.Printf("Warning: .env file not found")
 	}
 
-	// API anahtarını kontrol et
-	if os.Getenv("GOOGLE_MAPS_API_KEY") == "" {
-		log.Fatal("GOOGLE_MAPS_API_KEY is required")
-	}
-
 	// PostgreSQL bağlantısı
 	db, err := sql.Open("postgres", os.Getenv("POSTGRES_URI"))
 	if err != nil {
@@ -101,12 +177,58 @@ func main() {
 	}))
 
 	// Services
-	stationService := services.NewStationService()
-	mapService := services.NewMapService()
+	providers := []services.StationProvider{services.NewTrugoProvider()}
+	providers = append(providers, services.OCPIProvidersFromEnv()...)
+	stationService := services.NewStationService(providers...)
+
+	// Her yenilemede soket sayaçlarını geçmiş tablosuna yaz
+	retention, _ := time.ParseDuration(os.Getenv("AVAILABILITY_RETENTION"))
+	availabilityStore := services.NewAvailabilityStore(db, retention)
+	stationService.OnRefresh(availabilityStore.RecordRefresh)
+
+	// Eklenen, kaldırılan, taşınan istasyonları günlüğe yaz
+	changeLog := services.NewChangeLog(db)
+	changeLog.Start(context.Background())
+	stationService.OnChanges(changeLog.RecordChanges)
+
+	// Webhook teslimatları, katalog ve yorum olaylarını dinler
+	webhookStore := services.NewWebhookStore(db)
+	services.NewWebhookDispatcher(webhookStore, stationService.Events(), nil).Start(context.Background())
+
+	stationService.Start(context.Background())
+	// Harita API sonuçları önbelleği; ROUTE_CACHE_PERSIST=true ise Postgres'e de yazılır
+	var routeCacheTTL time.Duration
+	if v := os.Getenv("ROUTE_CACHE_TTL"); v != "" {
+		if routeCacheTTL, err = time.ParseDuration(v); err != nil {
+			log.Printf("Geçersiz ROUTE_CACHE_TTL %q, varsayılan kullanılıyor: %v", v, err)
+		}
+	}
+	var routeCacheSize int
+	if v := os.Getenv("ROUTE_CACHE_SIZE"); v != "" {
+		if routeCacheSize, err = strconv.Atoi(v); err != nil {
+			log.Printf("Geçersiz ROUTE_CACHE_SIZE %q, varsayılan kullanılıyor: %v", v, err)
+		}
+	}
+	var routeCacheDB *sql.DB
+	if os.Getenv("ROUTE_CACHE_PERSIST") == "true" {
+		routeCacheDB = db
+	}
+	routeCache := services.NewRouteCache(routeCacheDB, routeCacheTTL, routeCacheSize)
+	mapService := services.NewMapService(services.RoutingProvidersFromEnv(routeCache)...)
 
 	// Handlers
-	reviewHandler := handlers.NewReviewHandler(db)
+	reviewHandler := handlers.NewReviewHandler(db, stationService)
 	stationHandler := handlers.NewStationHandler(stationService, mapService, reviewHandler)
+	// Tahminler sürücülerin yerel saatine göre gruplanır
+	predictionZone, err := time.LoadLocation(envOrDefault("PREDICTION_TIMEZONE", "Europe/Istanbul"))
+	if err != nil {
+		log.Fatal("Geçersiz PREDICTION_TIMEZONE:", err)
+	}
+	predictor := services.NewAvailabilityPredictor(availabilityStore, predictionZone)
+	availabilityHandler := handlers.NewAvailabilityHandler(availabilityStore, predictor, stationService)
+	changeHandler := handlers.NewChangeHandler(changeLog)
+	webhookHandler := handlers.NewWebhookHandler(webhookStore)
+	routingHandler := handlers.NewRoutingHandler(routeCache)
 
 	// Rate limiter oluştur (10 istek/dakika)
 	rateLimiter := middleware.NewIPRateLimiter(rate.Every(time.Minute), 10)
@@ -115,20 +237,74 @@ func main() {
 	api := router.Group("/api")
 	{
 		api.GET("/stations", stationHandler.GetStations)
+		api.GET("/stations/status", stationHandler.GetCatalogStatus)
+		api.GET("/stations/changes", changeHandler.GetChanges)
 		api.GET("/stations/:id", stationHandler.GetStationDetails)
-		api.GET("/stations/nearby", stationHandler.GetNearbyStations)
+		// mode=driving, Distance Matrix'i çağırdığı için distance ile aynı limite tabidir
+		drivingMode := func(c *gin.Context) bool { return c.Query("mode") == "driving" }
+		api.GET("/stations/nearby", middleware.RateLimitWhen(rateLimiter, drivingMode), stationHandler.GetNearbyStations)
+		api.GET("/stations/viewport", stationHandler.GetViewport)
+		api.GET("/stations/stream", stationHandler.StreamStations)
+		api.GET("/stations/ws", stationHandler.StationSocket)
 
 		// Distance ve route endpoint'lerine rate limit uygula
 		distanceGroup := api.Group("/")
 		distanceGroup.Use(middleware.RateLimitMiddleware(rateLimiter))
 		{
 			distanceGroup.POST("/stations/distance", stationHandler.CalculateDistance)
+			distanceGroup.POST("/stations/distance/matrix", stationHandler.GetDistanceMatrix)
 			distanceGroup.POST("/stations/route", stationHandler.GetRoute)
+			distanceGroup.POST("/stations/route/plan", stationHandler.PlanRoute)
+			distanceGroup.POST("/stations/corridor", stationHandler.GetStationsAlongRoute)
 		}
 
 		// Review route'larını ekle
 		api.GET("/stations/:id/reviews", reviewHandler.GetStationReviews)
 		api.POST("/stations/:id/reviews", reviewHandler.CreateReview)
+
+		api.GET("/stations/:id/availability", availabilityHandler.GetAvailabilityHistory)
+		api.GET("/stations/:id/predictions", availabilityHandler.GetPredictions)
+
+		// Webhook ve rota önbelleği yönetimi (yalnızca WEBHOOK_ADMIN_TOKEN tanımlıysa)
+		if token := os.Getenv("WEBHOOK_ADMIN_TOKEN"); token != "" {
+			api.GET("/routing/cache", middleware.BearerTokenAuth(token), routingHandler.GetCacheStats)
+
+			webhooks := api.Group("/webhooks")
+			webhooks.Use(middleware.BearerTokenAuth(token))
+			{
+				webhooks.POST("", webhookHandler.CreateSubscription)
+				webhooks.GET("", webhookHandler.ListSubscriptions)
+				webhooks.DELETE("/:id", webhookHandler.DeleteSubscription)
+				webhooks.GET("/:id/deliveries", webhookHandler.GetDeliveries)
+				webhooks.GET("/dead-letters", webhookHandler.GetDeadLetters)
+				webhooks.POST("/deliveries/:id/retry", webhookHandler.RetryDelivery)
+			}
+		} else {
+			log.Println("WEBHOOK_ADMIN_TOKEN tanımlı değil, webhook ve rota önbelleği yönetim endpoint'leri kapalı")
+		}
+	}
+
+	// OCPI 2.2.1 Locations modülü (yalnızca token tanımlıysa)
+	if tokens := splitEnvList("OCPI_SERVER_TOKENS"); len(tokens) > 0 {
+		ocpiHandler := handlers.NewOCPIHandler(
+			stationService,
+			os.Getenv("OCPI_PUBLIC_URL"),
+			envOrDefault("OCPI_COUNTRY_CODE", "TR"),
+			envOrDefault("OCPI_PARTY_ID", "CSB"),
+		)
+
+		ocpi := router.Group("/ocpi")
+		ocpi.Use(middleware.OCPITokenAuth(tokens))
+		{
+			ocpi.GET("/versions", ocpiHandler.GetVersions)
+			ocpi.GET("/2.2.1", ocpiHandler.GetVersionDetails)
+			ocpi.GET("/2.2.1/locations", ocpiHandler.GetLocations)
+			ocpi.GET("/2.2.1/locations/:location_id", ocpiHandler.GetLocation)
+			ocpi.GET("/2.2.1/locations/:location_id/:evse_uid", ocpiHandler.GetEVSE)
+			ocpi.GET("/2.2.1/locations/:location_id/:evse_uid/:connector_id", ocpiHandler.GetConnector)
+		}
+	} else {
+		log.Println("OCPI_SERVER_TOKENS tanımlı değil, OCPI endpoint'leri kapalı")
 	}
 
 	log.Printf("Server starting on 0.0.0.0:3001")
@@ -0,0 +1,160 @@
+package handlers
+
+import (
+    "charging-stations-backend/internal/models"
+    "charging-stations-backend/internal/services"
+    "log"
+    "net/http"
+    "strconv"
+    "strings"
+    "time"
+
+    "github.com/gin-gonic/gin"
+)
+
+const (
+    defaultHistoryRange  = 24 * time.Hour
+    defaultHistoryBucket = time.Hour
+    minHistoryBucket     = 5 * time.Minute
+    maxHistoryBuckets    = 2000
+)
+
+type AvailabilityHandler struct {
+    store          *services.AvailabilityStore
+    predictor      *services.AvailabilityPredictor
+    stationService *services.StationService
+}
+
+func NewAvailabilityHandler(store *services.AvailabilityStore, predictor *services.AvailabilityPredictor, ss *services.StationService) *AvailabilityHandler {
+    return &AvailabilityHandler{
+        store:          store,
+        predictor:      predictor,
+        stationService: ss,
+    }
+}
+
+// GetAvailabilityHistory, istasyonun geçmiş doluluk verisini bucket'lara bölerek döndürür.
+// Parametreler: from, to (RFC3339; varsayılan son 24 saat), bucket (ör. 15m, 1h)
+func (h *AvailabilityHandler) GetAvailabilityHistory(c *gin.Context) {
+    stationID := h.stationService.ResolveKey(c.Param("id"))
+
+    to := time.Now()
+    if v := c.Query("to"); v != "" {
+        t, err := time.Parse(time.RFC3339, v)
+        if err != nil {
+            c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid to"})
+            return
+        }
+        to = t
+    }
+    from := to.Add(-defaultHistoryRange)
+    if v := c.Query("from"); v != "" {
+        t, err := time.Parse(time.RFC3339, v)
+        if err != nil {
+            c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid from"})
+            return
+        }
+        from = t
+    }
+    if !from.Before(to) {
+        c.JSON(http.StatusBadRequest, gin.H{"error": "from must be before to"})
+        return
+    }
+
+    bucket := defaultHistoryBucket
+    if v := c.Query("bucket"); v != "" {
+        d, err := time.ParseDuration(v)
+        if err != nil || d < minHistoryBucket {
+            c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid bucket, minimum is 5m"})
+            return
+        }
+        bucket = d
+    }
+    if to.Sub(from)/bucket > maxHistoryBuckets {
+        c.JSON(http.StatusBadRequest, gin.H{"error": "Range too large for bucket size"})
+        return
+    }
+
+    buckets, err := h.store.History(c.Request.Context(), stationID, from, to, bucket)
+    if err != nil {
+        log.Printf("Soket geçmişi okunamadı: %v", err)
+        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load availability history"})
+        return
+    }
+
+    c.JSON(http.StatusOK, gin.H{
+        "station_id": stationID,
+        "from":       from,
+        "to":         to,
+        "bucket":     bucket.String(),
+        "buckets":    buckets,
+    })
+}
+
+var weekdayNames = map[string]int{
+    "sun": 0, "mon": 1, "tue": 2, "wed": 3, "thu": 4, "fri": 5, "sat": 6,
+}
+
+// GetPredictions, istasyonda belirli bir hafta günü ve saatte boş AC/DC soket
+// bulma olasılığını döndürür. weekday (0-6, 0 = Pazar, ya da mon/tue/...) ve
+// hour (0-23) verilmezse tüm haftanın tablosu döner.
+func (h *AvailabilityHandler) GetPredictions(c *gin.Context) {
+    station := h.stationService.GetStation(c.Param("id"))
+    if station == nil {
+        c.JSON(http.StatusNotFound, gin.H{"error": "İstasyon bulunamadı"})
+        return
+    }
+
+    // Soket listesi bilinmiyorsa iki tip için de tahmin verilir
+    hasAC, hasDC := len(station.Connectors) == 0, len(station.Connectors) == 0
+    for _, connector := range station.Connectors {
+        if connector.PowerType == models.PowerTypeDC {
+            hasDC = true
+        } else {
+            hasAC = true
+        }
+    }
+
+    weekdayParam, hourParam := c.Query("weekday"), c.Query("hour")
+    if weekdayParam == "" && hourParam == "" {
+        profile, err := h.predictor.WeeklyProfile(c.Request.Context(), station.Key, hasAC, hasDC)
+        if err != nil {
+            log.Printf("Doluluk tahmini hesaplanamadı: %v", err)
+            c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to compute predictions"})
+            return
+        }
+        c.JSON(http.StatusOK, gin.H{
+            "station_id":  station.Key,
+            "timezone":    h.predictor.Location().String(),
+            "predictions": profile,
+        })
+        return
+    }
+
+    weekday, ok := weekdayNames[strings.ToLower(weekdayParam)]
+    if !ok {
+        var err error
+        weekday, err = strconv.Atoi(weekdayParam)
+        if err != nil || weekday < 0 || weekday > 6 {
+            c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid weekday"})
+            return
+        }
+    }
+    hour, err := strconv.Atoi(hourParam)
+    if err != nil || hour < 0 || hour > 23 {
+        c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid hour"})
+        return
+    }
+
+    prediction, err := h.predictor.Predict(c.Request.Context(), station.Key, weekday, hour, hasAC, hasDC)
+    if err != nil {
+        log.Printf("Doluluk tahmini hesaplanamadı: %v", err)
+        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to compute predictions"})
+        return
+    }
+    c.JSON(http.StatusOK, gin.H{
+        "station_id": station.Key,
+        "timezone":   h.predictor.Location().String(),
+        "prediction": prediction,
+    })
+}
@@ -0,0 +1,75 @@
+package handlers
+
+import (
+    "charging-stations-backend/internal/services"
+    "log"
+    "net/http"
+    "strconv"
+    "time"
+
+    "github.com/gin-gonic/gin"
+)
+
+const (
+    defaultChangesLimit = 100
+    maxChangesLimit     = 1000
+)
+
+type ChangeHandler struct {
+    changeLog *services.ChangeLog
+}
+
+func NewChangeHandler(changeLog *services.ChangeLog) *ChangeHandler {
+    return &ChangeHandler{changeLog: changeLog}
+}
+
+// GetChanges, istasyon değişiklik günlüğünü döndürür.
+// Parametreler: since (RFC3339, varsayılan son 24 saat), type (virgüllü),
+// limit, after_id (önceki yanıtın last_id değeri, sonraki sayfa için)
+func (h *ChangeHandler) GetChanges(c *gin.Context) {
+    since := time.Now().Add(-24 * time.Hour)
+    if v := c.Query("since"); v != "" {
+        t, err := time.Parse(time.RFC3339, v)
+        if err != nil {
+            c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid since"})
+            return
+        }
+        since = t
+    }
+
+    var afterID int64
+    if v := c.Query("after_id"); v != "" {
+        id, err := strconv.ParseInt(v, 10, 64)
+        if err != nil || id < 0 {
+            c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid after_id"})
+            return
+        }
+        afterID = id
+    }
+
+    limit, err := strconv.Atoi(c.DefaultQuery("limit", strconv.Itoa(defaultChangesLimit)))
+    if err != nil || limit <= 0 {
+        c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid limit"})
+        return
+    }
+    if limit > maxChangesLimit {
+        limit = maxChangesLimit
+    }
+
+    changes, err := h.changeLog.Since(c.Request.Context(), since, afterID, queryList(c, "type"), limit)
+    if err != nil {
+        log.Printf("Değişiklik günlüğü okunamadı: %v", err)
+        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load station changes"})
+        return
+    }
+
+    response := gin.H{
+        "since":    since,
+        "changes":  changes,
+        "has_more": len(changes) == limit,
+    }
+    if len(changes) > 0 {
+        response["last_id"] = changes[len(changes)-1].ID
+    }
+    c.JSON(http.StatusOK, response)
+}
@@ -0,0 +1,240 @@
+package handlers
+
+import (
+    "charging-stations-backend/internal/models"
+    "charging-stations-backend/internal/services"
+    "encoding/json"
+    "fmt"
+    "log"
+    "net/http"
+    "net/url"
+    "strconv"
+    "strings"
+    "time"
+
+    "github.com/gin-gonic/gin"
+)
+
+const (
+    ocpiVersion          = "2.2.1"
+    ocpiDefaultPageLimit = 50
+    ocpiMaxPageLimit     = 100
+)
+
+// OCPIHandler, istasyon kataloğunu salt okunur OCPI 2.2.1 CPO Locations
+// modülü olarak sunar
+type OCPIHandler struct {
+    stationService *services.StationService
+    baseURL        string
+    countryCode    string
+    partyID        string
+}
+
+// baseURL boşsa bağlantılar isteğin geldiği adresten üretilir
+func NewOCPIHandler(ss *services.StationService, baseURL, countryCode, partyID string) *OCPIHandler {
+    return &OCPIHandler{
+        stationService: ss,
+        baseURL:        strings.TrimRight(baseURL, "/"),
+        countryCode:    countryCode,
+        partyID:        partyID,
+    }
+}
+
+func (h *OCPIHandler) GetVersions(c *gin.Context) {
+    h.respond(c, http.StatusOK, []gin.H{
+        {"version": ocpiVersion, "url": h.url(c, "/ocpi/"+ocpiVersion)},
+    })
+}
+
+func (h *OCPIHandler) GetVersionDetails(c *gin.Context) {
+    h.respond(c, http.StatusOK, gin.H{
+        "version": ocpiVersion,
+        "endpoints": []gin.H{
+            {
+                "identifier": "locations",
+                "role":       "SENDER",
+                "url":        h.url(c, "/ocpi/"+ocpiVersion+"/locations"),
+            },
+        },
+    })
+}
+
+// GetLocations, date_from/date_to filtreli ve offset/limit sayfalı lokasyon listesi döndürür
+func (h *OCPIHandler) GetLocations(c *gin.Context) {
+    var dateFrom, dateTo time.Time
+    var err error
+    if v := c.Query("date_from"); v != "" {
+        if dateFrom, err = time.Parse(time.RFC3339, v); err != nil {
+            h.error(c, http.StatusBadRequest, models.OCPIStatusClientError, "Invalid date_from")
+            return
+        }
+    }
+    if v := c.Query("date_to"); v != "" {
+        if dateTo, err = time.Parse(time.RFC3339, v); err != nil {
+            h.error(c, http.StatusBadRequest, models.OCPIStatusClientError, "Invalid date_to")
+            return
+        }
+    }
+
+    offset, err := strconv.Atoi(c.DefaultQuery("offset", "0"))
+    if err != nil || offset < 0 {
+        h.error(c, http.StatusBadRequest, models.OCPIStatusClientError, "Invalid offset")
+        return
+    }
+    limit, err := strconv.Atoi(c.DefaultQuery("limit", strconv.Itoa(ocpiDefaultPageLimit)))
+    if err != nil || limit <= 0 {
+        h.error(c, http.StatusBadRequest, models.OCPIStatusClientError, "Invalid limit")
+        return
+    }
+    if limit > ocpiMaxPageLimit {
+        limit = ocpiMaxPageLimit
+    }
+
+    // date_from dahil, date_to hariç (OCPI 2.2.1)
+    var locations []models.OCPILocation
+    for _, location := range h.locations() {
+        if !dateFrom.IsZero() && location.LastUpdated.Before(dateFrom) {
+            continue
+        }
+        if !dateTo.IsZero() && !location.LastUpdated.Before(dateTo) {
+            continue
+        }
+        locations = append(locations, location)
+    }
+
+    total := len(locations)
+    end := offset + limit
+    if offset > total {
+        offset = total
+    }
+    if end > total {
+        end = total
+    }
+    page := locations[offset:end]
+    if page == nil {
+        page = []models.OCPILocation{}
+    }
+
+    c.Header("X-Total-Count", strconv.Itoa(total))
+    c.Header("X-Limit", strconv.Itoa(ocpiMaxPageLimit))
+    if end < total {
+        q := c.Request.URL.Query()
+        q.Set("offset", strconv.Itoa(end))
+        q.Set("limit", strconv.Itoa(limit))
+        next := h.url(c, c.Request.URL.Path) + "?" + q.Encode()
+        c.Header("Link", fmt.Sprintf(`<%s>; rel="next"`, next))
+    }
+
+    h.respond(c, http.StatusOK, page)
+}
+
+func (h *OCPIHandler) GetLocation(c *gin.Context) {
+    location, ok := h.findLocation(c)
+    if !ok {
+        return
+    }
+    h.respond(c, http.StatusOK, location)
+}
+
+func (h *OCPIHandler) GetEVSE(c *gin.Context) {
+    location, ok := h.findLocation(c)
+    if !ok {
+        return
+    }
+    for _, evse := range location.EVSEs {
+        if evse.UID == c.Param("evse_uid") {
+            h.respond(c, http.StatusOK, evse)
+            return
+        }
+    }
+    h.error(c, http.StatusNotFound, models.OCPIStatusUnknownObject, "Unknown EVSE")
+}
+
+func (h *OCPIHandler) GetConnector(c *gin.Context) {
+    location, ok := h.findLocation(c)
+    if !ok {
+        return
+    }
+    for _, evse := range location.EVSEs {
+        if evse.UID != c.Param("evse_uid") {
+            continue
+        }
+        for _, connector := range evse.Connectors {
+            if connector.ID == c.Param("connector_id") {
+                h.respond(c, http.StatusOK, connector)
+                return
+            }
+        }
+    }
+    h.error(c, http.StatusNotFound, models.OCPIStatusUnknownObject, "Unknown connector")
+}
+
+func (h *OCPIHandler) findLocation(c *gin.Context) (models.OCPILocation, bool) {
+    station := h.stationService.GetStation(c.Param("location_id"))
+    if station == nil {
+        h.error(c, http.StatusNotFound, models.OCPIStatusUnknownObject, "Unknown location")
+        return models.OCPILocation{}, false
+    }
+    location, ok := services.StationToOCPILocation(*station, h.countryCode, h.partyID, h.catalogTime())
+    if !ok {
+        h.error(c, http.StatusNotFound, models.OCPIStatusUnknownObject, "Unknown location")
+        return models.OCPILocation{}, false
+    }
+    return location, true
+}
+
+func (h *OCPIHandler) locations() []models.OCPILocation {
+    stations := h.stationService.GetStations()
+    updated := h.catalogTime()
+
+    locations := make([]models.OCPILocation, 0, len(stations))
+    for _, station := range stations {
+        // Dışa aktarılabilir soketi olmayan istasyonlar listelenmez
+        if location, ok := services.StationToOCPILocation(station, h.countryCode, h.partyID, updated); ok {
+            locations = append(locations, location)
+        }
+    }
+    return locations
+}
+
+// Kaynağı last_updated taşımayan istasyonlar için son katalog yenileme zamanı
+func (h *OCPIHandler) catalogTime() time.Time {
+    if t := h.stationService.Status().LastRefresh; t != nil {
+        return *t
+    }
+    return time.Now()
+}
+
+func (h *OCPIHandler) url(c *gin.Context, path string) string {
+    if h.baseURL != "" {
+        return h.baseURL + path
+    }
+    scheme := "http"
+    if c.Request.TLS != nil || c.GetHeader("X-Forwarded-Proto") == "https" {
+        scheme = "https"
+    }
+    u := url.URL{Scheme: scheme, Host: c.Request.Host, Path: path}
+    return u.String()
+}
+
+func (h *OCPIHandler) respond(c *gin.Context, status int, data interface{}) {
+    raw, err := json.Marshal(data)
+    if err != nil {
+        log.Printf("OCPI yanıtı oluşturulamadı: %v", err)
+        h.error(c, http.StatusInternalServerError, models.OCPIStatusServerError, "Internal error")
+        return
+    }
+    c.JSON(status, models.OCPIResponse{
+        Data:       raw,
+        StatusCode: models.OCPIStatusSuccess,
+        Timestamp:  time.Now().UTC(),
+    })
+}
+
+func (h *OCPIHandler) error(c *gin.Context, status, code int, message string) {
+    c.JSON(status, models.OCPIResponse{
+        StatusCode:    code,
+        StatusMessage: message,
+        Timestamp:     time.Now().UTC(),
+    })
+}
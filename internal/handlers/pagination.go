@@ -0,0 +1,69 @@
+package handlers
+
+import (
+    "charging-stations-backend/internal/services"
+    "fmt"
+    "strconv"
+
+    "github.com/gin-gonic/gin"
+)
+
+// Tüm istasyon listeleri bu zarfla döner
+type StationPage struct {
+    Stations   []services.Station      `json:"stations"`
+    Total      int                     `json:"total"`
+    Offset     int                     `json:"offset"`
+    Limit      int                     `json:"limit"`
+    NextOffset *int                    `json:"next_offset,omitempty"`
+    Facets     *services.StationFacets `json:"facets,omitempty"`
+}
+
+type pageParams struct {
+    offset int
+    limit  int
+}
+
+// parsePageParams, offset ve limit parametrelerini doğrular; limit maxLimit ile sınırlanır
+func parsePageParams(c *gin.Context, defaultLimit, maxLimit int) (pageParams, error) {
+    offset, err := strconv.Atoi(c.DefaultQuery("offset", "0"))
+    if err != nil || offset < 0 {
+        return pageParams{}, fmt.Errorf("Invalid offset")
+    }
+
+    limit, err := strconv.Atoi(c.DefaultQuery("limit", strconv.Itoa(defaultLimit)))
+    if err != nil || limit <= 0 {
+        return pageParams{}, fmt.Errorf("Invalid limit")
+    }
+    if limit > maxLimit {
+        limit = maxLimit
+    }
+
+    return pageParams{offset: offset, limit: limit}, nil
+}
+
+// newStationPage, sıralanmış listeden istenen sayfayı keser
+func newStationPage(stations []services.Station, total int, p pageParams) StationPage {
+    start := p.offset
+    if start > len(stations) {
+        start = len(stations)
+    }
+    end := start + p.limit
+    if end > len(stations) {
+        end = len(stations)
+    }
+
+    page := StationPage{
+        Stations: stations[start:end],
+        Total:    total,
+        Offset:   p.offset,
+        Limit:    p.limit,
+    }
+    if page.Stations == nil {
+        page.Stations = []services.Station{}
+    }
+    if p.offset+p.limit < total {
+        next := p.offset + p.limit
+        page.NextOffset = &next
+    }
+    return page
+}
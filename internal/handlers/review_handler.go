@@ -2,28 +2,33 @@ package handlers
 
 import (
     "database/sql"
+    "time"
     "github.com/gin-gonic/gin"
     "charging-stations-backend/internal/models"
+    "charging-stations-backend/internal/services"
     "log"
     "net/http"
 )
 
 type ReviewHandler struct {
-    db *sql.DB
+    db             *sql.DB
+    stationService *services.StationService
 }
 
-func NewReviewHandler(db *sql.DB) *ReviewHandler {
+func NewReviewHandler(db *sql.DB, ss *services.StationService) *ReviewHandler {
     return &ReviewHandler{
-        db: db,
+        db:             db,
+        stationService: ss,
     }
 }
 
 func (h *ReviewHandler) CreateReview(c *gin.Context) {
-    stationID := c.Param("id")
-    if stationID == "" {
+    if c.Param("id") == "" {
         c.JSON(http.StatusBadRequest, gin.H{"error": "Station ID is required"})
         return
     }
+    // Yorumlar ad alanlı istasyon anahtarıyla saklanır (ör. "trugo:123")
+    stationID := h.stationService.ResolveKey(c.Param("id"))
 
     var review struct {
         Rating  float64 `json:"rating" binding:"required,min=1,max=5"`
@@ -39,11 +44,15 @@ func (h *ReviewHandler) CreateReview(c *gin.Context) {
     query := `
         INSERT INTO reviews (station_id, rating, comment, created_at, updated_at)
         VALUES ($1, $2, $3, NOW(), NOW())
-        RETURNING id
+        RETURNING id, created_at, updated_at
     `
 
-    var id int
-    err := h.db.QueryRow(query, stationID, review.Rating, review.Comment).Scan(&id)
+    created := models.Review{
+        StationID: stationID,
+        Rating:    review.Rating,
+        Comment:   review.Comment,
+    }
+    err := h.db.QueryRow(query, stationID, review.Rating, review.Comment).Scan(&created.ID, &created.CreatedAt, &created.UpdatedAt)
     if err != nil {
         log.Printf("Error creating review: %v", err)
         c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create review"})
@@ -56,16 +65,42 @@ func (h *ReviewHandler) CreateReview(c *gin.Context) {
         log.Printf("Error getting updated stats: %v", err)
     }
 
+    h.publishReview(created, stats)
+
     // Başarılı yanıtla birlikte güncel istatistikleri de gönder
     c.JSON(http.StatusCreated, gin.H{
-        "id": id,
+        "id": created.ID,
         "message": "Review created successfully",
         "stats": stats,
     })
 }
 
+// publishReview, yeni yorumu ve güncel puanı istasyon abonelerine yayınlar
+func (h *ReviewHandler) publishReview(review models.Review, stats *models.StationStats) {
+    now := time.Now()
+    station := h.stationService.GetStation(review.StationID)
+
+    events := []services.StationEvent{{
+        Type:       services.EventReviewCreated,
+        StationKey: review.StationID,
+        Station:    station,
+        Review:     &review,
+        Time:       now,
+    }}
+    if stats != nil {
+        events = append(events, services.StationEvent{
+            Type:       services.EventRatingChanged,
+            StationKey: review.StationID,
+            Station:    station,
+            Stats:      stats,
+            Time:       now,
+        })
+    }
+    h.stationService.Events().Publish(events...)
+}
+
 func (h *ReviewHandler) GetStationReviews(c *gin.Context) {
-    stationID := c.Param("id")
+    stationID := h.stationService.ResolveKey(c.Param("id"))
     log.Printf("İstasyon yorumları istendi: StationID=%s", stationID)
 
     query := `
@@ -125,4 +160,31 @@ func (h *ReviewHandler) GetStationStats(stationID string) (*models.StationStats,
     }
 
     return stats, nil
-} 
\ No newline at end of file
+}
+
+// GetAllStationStats, tüm istasyonların puan istatistiklerini tek sorguda döndürür
+func (h *ReviewHandler) GetAllStationStats() (map[string]models.StationStats, error) {
+    query := `
+        SELECT station_id,
+               COALESCE(ROUND(AVG(rating)::numeric, 1), 0) as average_rating,
+               COUNT(*) as review_count
+        FROM reviews
+        GROUP BY station_id`
+
+    rows, err := h.db.Query(query)
+    if err != nil {
+        return nil, err
+    }
+    defer rows.Close()
+
+    stats := make(map[string]models.StationStats)
+    for rows.Next() {
+        var stationID string
+        var s models.StationStats
+        if err := rows.Scan(&stationID, &s.AverageRating, &s.ReviewCount); err != nil {
+            return nil, err
+        }
+        stats[stationID] = s
+    }
+    return stats, rows.Err()
+}
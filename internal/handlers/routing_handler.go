@@ -0,0 +1,21 @@
+package handlers
+
+import (
+    "charging-stations-backend/internal/services"
+    "net/http"
+
+    "github.com/gin-gonic/gin"
+)
+
+type RoutingHandler struct {
+    cache *services.RouteCache
+}
+
+func NewRoutingHandler(cache *services.RouteCache) *RoutingHandler {
+    return &RoutingHandler{cache: cache}
+}
+
+// GetCacheStats, harita API önbelleğinin isabet/ıska sayaçlarını döndürür
+func (h *RoutingHandler) GetCacheStats(c *gin.Context) {
+    c.JSON(http.StatusOK, h.cache.Stats())
+}
@@ -1,8 +1,10 @@
 package handlers
 
 import (
+    "errors"
     "net/http"
     "strconv"
+    "strings"
     "github.com/gin-gonic/gin"
     "charging-stations-backend/internal/services"
     "charging-stations-backend/internal/models"
@@ -11,10 +13,29 @@ import (
     "log"
 )
 
+const (
+    defaultStationPageLimit = 50
+    maxStationPageLimit     = 200
+    defaultNearbyLimit      = 10
+    maxNearbyLimit          = 100
+    // Yarıçapsız en yakın aramasında offset+limit bu sayıyı aşamaz
+    maxNearbyWindow = 1000
+    // Mesafe dışı sıralamada yarıçap verilmezse kullanılır
+    defaultNearbyRadiusKM   = 25
+    defaultCorridorRadiusKM = 5
+    maxMatrixOrigins        = 25
+    maxMatrixDestinations   = 100
+    // mode=driving'de yol mesafesiyle yeniden sıralanan aday sayısı
+    defaultDrivingCandidates = 20
+    maxDrivingCandidates     = 50
+)
+
 type StationHandler struct {
     stationService *services.StationService
     mapService     *services.MapService
     reviewHandler  *ReviewHandler
+    routePlanner   *services.RoutePlanner
+    drivingRanker  *services.DrivingRanker
 }
 
 func NewStationHandler(ss *services.StationService, ms *services.MapService, rh *ReviewHandler) *StationHandler {
@@ -22,14 +43,120 @@ func NewStationHandler(ss *services.StationService, ms *services.MapService, rh
         stationService: ss,
         mapService:     ms,
         reviewHandler:  rh,
+        routePlanner:   services.NewRoutePlanner(ss, ms),
+        drivingRanker:  services.NewDrivingRanker(ms),
     }
 }
 
+// GetStations, filtrelenmiş istasyonları sayfalı olarak ve filtre çipleri için facet sayılarıyla döndürür.
+// Filtreler: brand, connector, min_power, ac_available, dc_available, color, min_rating, q
+// Sayfalama/sıralama: offset, limit, sort (distance|rating|available_sockets|name), order, lat, lng
 func (h *StationHandler) GetStations(c *gin.Context) {
-    // Tüm istasyonları getir, ama istatistikler olmadan
-    stations := h.stationService.GetStations()
-	fmt.Println("stations", stations)
-    c.JSON(http.StatusOK, stations)
+    filter, err := parseStationFilter(c)
+    if err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+        return
+    }
+
+    page, err := parsePageParams(c, defaultStationPageLimit, maxStationPageLimit)
+    if err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+        return
+    }
+
+    sortBy, desc, err := services.ParseStationSort(c.Query("sort"), c.Query("order"))
+    if err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+        return
+    }
+
+    stations := h.withRatings(h.stationService.GetStations())
+
+    // Konum verildiyse mesafe her zaman eklenir; mesafeye göre sıralama için zorunludur
+    if c.Query("lat") != "" || c.Query("lng") != "" || sortBy == services.SortByDistance {
+        lat, lon, err := parseLatLng(c)
+        if err != nil {
+            c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+            return
+        }
+        services.WithDistances(stations, lat, lon)
+    }
+
+    facets := services.ComputeFacets(stations, filter)
+    filtered := services.FilterStations(stations, filter)
+    services.SortStations(filtered, sortBy, desc)
+
+    result := newStationPage(filtered, len(filtered), page)
+    result.Facets = &facets
+    c.JSON(http.StatusOK, result)
+}
+
+// withRatings, istasyonlara puan istatistiklerini ekler. Veritabanı hatasında
+// istasyonlar puansız döner.
+func (h *StationHandler) withRatings(stations []services.Station) []services.Station {
+    stats, err := h.reviewHandler.GetAllStationStats()
+    if err != nil {
+        log.Printf("Error getting station stats: %v", err)
+        return stations
+    }
+
+    for i := range stations {
+        if s, ok := stats[stations[i].Key]; ok {
+            stations[i].AverageRating = s.AverageRating
+            stations[i].ReviewCount = s.ReviewCount
+        }
+    }
+    return stations
+}
+
+func parseStationFilter(c *gin.Context) (services.StationFilter, error) {
+    filter := services.StationFilter{
+        Brands:     queryList(c, "brand"),
+        Connectors: queryList(c, "connector"),
+        Colors:     queryList(c, "color"),
+        Query:      strings.TrimSpace(c.Query("q")),
+    }
+
+    var err error
+    if v := c.Query("min_power"); v != "" {
+        if filter.MinPowerKW, err = strconv.ParseFloat(v, 64); err != nil {
+            return filter, fmt.Errorf("Invalid min_power")
+        }
+    }
+    if v := c.Query("min_rating"); v != "" {
+        if filter.MinRating, err = strconv.ParseFloat(v, 64); err != nil {
+            return filter, fmt.Errorf("Invalid min_rating")
+        }
+    }
+    if v := c.Query("ac_available"); v != "" {
+        if filter.ACAvailable, err = strconv.ParseBool(v); err != nil {
+            return filter, fmt.Errorf("Invalid ac_available")
+        }
+    }
+    if v := c.Query("dc_available"); v != "" {
+        if filter.DCAvailable, err = strconv.ParseBool(v); err != nil {
+            return filter, fmt.Errorf("Invalid dc_available")
+        }
+    }
+    return filter, nil
+}
+
+// queryList, hem tekrarlanan (?brand=a&brand=b) hem virgüllü (?brand=a,b) parametreleri okur
+func queryList(c *gin.Context, key string) []string {
+    var values []string
+    for _, v := range c.QueryArray(key) {
+        for _, item := range strings.Split(v, ",") {
+            if item = strings.TrimSpace(item); item != "" {
+                values = append(values, item)
+            }
+        }
+    }
+    return values
+}
+
+// Katalog önbelleğinin tazeliğini döndürür
+func (h *StationHandler) GetCatalogStatus(c *gin.Context) {
+    c.JSON(http.StatusOK, h.stationService.Status())
 }
 
 func (h *StationHandler) GetStationDetails(c *gin.Context) {
@@ -43,9 +170,9 @@ func (h *StationHandler) GetStationDetails(c *gin.Context) {
     }
 
     // İstasyon için güncel istatistikleri al
-    stats, err := h.reviewHandler.GetStationStats(stationID)
+    stats, err := h.reviewHandler.GetStationStats(station.Key)
     if err != nil {
-        log.Printf("Error getting stats for station %s: %v", stationID, err)
+        log.Printf("Error getting stats for station %s: %v", station.Key, err)
         stats = &models.StationStats{
             AverageRating: 0,
             ReviewCount:   0,
@@ -62,39 +189,234 @@ func (h *StationHandler) GetStationDetails(c *gin.Context) {
     c.JSON(http.StatusOK, station)
 }
 
+// GetNearbyStations, noktaya en yakın istasyonları sayfalı döndürür. radius_km
+// verilirse yalnızca yarıçap içindekiler, sort verilirse o yarıçaptaki
+// istasyonlar istenen alana göre sıralanır. mode=driving ile kuş uçuşu en
+// yakın adaylar sürüş süresine göre yeniden sıralanır.
 func (h *StationHandler) GetNearbyStations(c *gin.Context) {
-    lat, err := strconv.ParseFloat(c.Query("lat"), 64)
+    lat, lon, err := parseLatLng(c)
     if err != nil {
-        c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid latitude"})
+        c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
         return
     }
 
-    lon, err := strconv.ParseFloat(c.Query("lng"), 64)
+    switch c.DefaultQuery("mode", "straight") {
+    case "straight":
+    case "driving":
+        h.getNearbyByDriving(c, lat, lon)
+        return
+    default:
+        c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid mode, expected straight or driving"})
+        return
+    }
+
+    page, err := parsePageParams(c, defaultNearbyLimit, maxNearbyLimit)
     if err != nil {
-        c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid longitude"})
+        c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
         return
     }
 
-    limit, err := strconv.Atoi(c.DefaultQuery("limit", "10"))
+    sortBy, desc, err := services.ParseStationSort(c.DefaultQuery("sort", services.SortByDistance), c.Query("order"))
     if err != nil {
-        c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid limit"})
+        c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
         return
     }
 
-    stations := h.stationService.GetNearbyStations(lat, lon, limit)
-    c.JSON(http.StatusOK, stations)
+    radius := 0.0
+    if v := c.Query("radius_km"); v != "" {
+        if radius, err = strconv.ParseFloat(v, 64); err != nil || radius <= 0 {
+            c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid radius_km"})
+            return
+        }
+    } else if sortBy != services.SortByDistance || desc {
+        radius = defaultNearbyRadiusKM
+    }
+
+    var stations []services.Station
+    var total int
+    if radius > 0 {
+        stations = h.stationService.GetStationsWithinRadius(lat, lon, radius)
+        total = len(stations)
+    } else {
+        // Büyük offset'ler tüm katalog üzerinde k-en-yakın aramasına dönüşmesin
+        total = h.stationService.Status().StationCount
+        if page.offset >= total {
+            c.JSON(http.StatusOK, newStationPage(nil, total, page))
+            return
+        }
+        if page.offset+page.limit > maxNearbyWindow {
+            c.JSON(http.StatusBadRequest, gin.H{
+                "error": fmt.Sprintf("offset+limit must not exceed %d without radius_km", maxNearbyWindow),
+            })
+            return
+        }
+        stations = h.stationService.GetNearbyStations(lat, lon, page.offset+page.limit)
+    }
+
+    if sortBy != services.SortByDistance || desc {
+        if sortBy == services.SortByRating {
+            stations = h.withRatings(stations)
+        }
+        services.SortStations(stations, sortBy, desc)
+    }
+
+    c.JSON(http.StatusOK, newStationPage(stations, total, page))
 }
 
+// getNearbyByDriving, kuş uçuşu en yakın adayları seçip yol mesafesi ve
+// tahmini varış süresiyle yeniden sıralar. Sayfalama yalnızca adaylar içinde yapılır.
+func (h *StationHandler) getNearbyByDriving(c *gin.Context, lat, lon float64) {
+    page, err := parsePageParams(c, defaultNearbyLimit, maxDrivingCandidates)
+    if err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+        return
+    }
+    if c.Query("sort") != "" || c.Query("order") != "" {
+        c.JSON(http.StatusBadRequest, gin.H{"error": "sort and order are not supported with mode=driving"})
+        return
+    }
+
+    candidates := defaultDrivingCandidates
+    if v := c.Query("candidates"); v != "" {
+        if candidates, err = strconv.Atoi(v); err != nil || candidates <= 0 {
+            c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid candidates"})
+            return
+        }
+    }
+    if candidates < page.offset+page.limit {
+        candidates = page.offset + page.limit
+    }
+    if candidates > maxDrivingCandidates {
+        c.JSON(http.StatusBadRequest, gin.H{
+            "error": fmt.Sprintf("At most %d stations can be ranked by driving distance", maxDrivingCandidates),
+        })
+        return
+    }
+
+    var stations []services.Station
+    if v := c.Query("radius_km"); v != "" {
+        radius, err := strconv.ParseFloat(v, 64)
+        if err != nil || radius <= 0 {
+            c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid radius_km"})
+            return
+        }
+        stations = h.stationService.GetStationsWithinRadius(lat, lon, radius)
+        if len(stations) > candidates {
+            stations = stations[:candidates]
+        }
+    } else {
+        stations = h.stationService.GetNearbyStations(lat, lon, candidates)
+    }
+
+    stations = h.drivingRanker.Rank(c.Request.Context(), lat, lon, stations)
+    c.JSON(http.StatusOK, newStationPage(stations, len(stations), page))
+}
+
+// GetViewport, harita görünümündeki istasyonları döndürür. bbox=minLng,minLat,maxLng,maxLat;
+// zoom ClusterMaxZoom altındaysa istasyonlar sunucu tarafında kümelenir.
+func (h *StationHandler) GetViewport(c *gin.Context) {
+    bbox, err := parseBBox(c.Query("bbox"))
+    if err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+        return
+    }
+
+    zoom, err := strconv.Atoi(c.Query("zoom"))
+    if err != nil || zoom < 0 || zoom > 22 {
+        c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid zoom"})
+        return
+    }
+
+    stations := h.stationService.GetStationsInBounds(bbox[1], bbox[0], bbox[3], bbox[2])
+    clusters, singles := services.ClusterStations(stations, zoom)
+
+    c.JSON(http.StatusOK, gin.H{
+        "zoom":      zoom,
+        "clustered": zoom < services.ClusterMaxZoom,
+        "total":     len(stations),
+        "clusters":  clusters,
+        "stations":  singles,
+    })
+}
+
+func parseLatLng(c *gin.Context) (float64, float64, error) {
+    lat, err := strconv.ParseFloat(c.Query("lat"), 64)
+    if err != nil || lat < -90 || lat > 90 {
+        return 0, 0, fmt.Errorf("Invalid latitude")
+    }
+
+    lon, err := strconv.ParseFloat(c.Query("lng"), 64)
+    if err != nil || lon < -180 || lon > 180 {
+        return 0, 0, fmt.Errorf("Invalid longitude")
+    }
+    return lat, lon, nil
+}
+
+// travelParams, zamana bağlı rota/mesafe isteklerinin ortak alanlarıdır.
+// Zamanlar "now", Unix saniyesi ya da RFC3339 olarak verilebilir.
+type travelParams struct {
+    DepartureTime string `json:"departure_time"`
+    ArrivalTime   string `json:"arrival_time"`
+    TrafficModel  string `json:"traffic_model"`
+    Alternatives  bool   `json:"alternatives"`
+}
+
+func (p travelParams) options() (services.TravelOptions, error) {
+    opts := services.TravelOptions{
+        TrafficModel: p.TrafficModel,
+        Alternatives: p.Alternatives,
+    }
+    if p.DepartureTime != "" {
+        t, now, err := services.ParseTravelTime(p.DepartureTime)
+        if err != nil {
+            return opts, fmt.Errorf("Invalid departure_time: %v", err)
+        }
+        opts.DepartureTime, opts.DepartNow = t, now
+    }
+    if p.ArrivalTime != "" {
+        t, now, err := services.ParseTravelTime(p.ArrivalTime)
+        if err != nil || now {
+            return opts, fmt.Errorf("Invalid arrival_time: expected unix seconds or RFC3339 time")
+        }
+        opts.ArrivalTime = t
+    }
+    return opts, opts.Validate()
+}
+
+// GetRoute, rotanın tam çizgisini, adımlarını ve sınırlarını döndürür.
+// ?polyline=encoded ile çizgiler nokta listesi yerine kodlu metin olarak döner.
+// departure_time/arrival_time, traffic_model ve alternatives (gövdede ya da
+// ?alternatives=true) ile zamana bağlı, trafikli ve alternatifli rota istenebilir.
 func (h *StationHandler) GetRoute(c *gin.Context) {
-    var req models.RouteRequest
+    var req struct {
+        models.RouteRequest
+        travelParams
+    }
     if err := c.ShouldBindJSON(&req); err != nil {
         c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
         return
     }
 
+    if c.Query("alternatives") == "true" {
+        req.Alternatives = true
+    }
+    opts, err := req.options()
+    if err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+        return
+    }
+
+    format := c.DefaultQuery("polyline", "points")
+    if format != "points" && format != "encoded" {
+        c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid polyline, expected points or encoded"})
+        return
+    }
+
     route, err := h.mapService.GetRoute(
+        c.Request.Context(),
         maps.LatLng{Lat: req.OriginLat, Lng: req.OriginLng},
         maps.LatLng{Lat: req.DestinationLat, Lng: req.DestinationLng},
+        opts,
     )
 
     if err != nil {
@@ -102,15 +424,137 @@ func (h *StationHandler) GetRoute(c *gin.Context) {
         return
     }
 
+    if format == "encoded" {
+        route.Encode()
+    }
     c.JSON(http.StatusOK, route)
 }
 
+// GetStationsAlongRoute, rotaya radius_km içindeki istasyonları yolculuk sırasıyla döndürür.
+// Rota ya origin/destination ile hesaplanır ya da kodlu polyline olarak verilir.
+func (h *StationHandler) GetStationsAlongRoute(c *gin.Context) {
+    var req struct {
+        OriginLat      *float64 `json:"origin_lat"`
+        OriginLng      *float64 `json:"origin_lng"`
+        DestinationLat *float64 `json:"destination_lat"`
+        DestinationLng *float64 `json:"destination_lng"`
+        Polyline       string   `json:"polyline"`
+        RadiusKM       float64  `json:"radius_km"`
+    }
+    if err := c.ShouldBindJSON(&req); err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+        return
+    }
+
+    if req.RadiusKM == 0 {
+        req.RadiusKM = defaultCorridorRadiusKM
+    }
+    if req.RadiusKM < 0 || req.RadiusKM > services.MaxCorridorRadiusKM {
+        c.JSON(http.StatusBadRequest, gin.H{
+            "error": fmt.Sprintf("radius_km must be between 0 and %d", services.MaxCorridorRadiusKM),
+        })
+        return
+    }
+
+    var points []maps.LatLng
+    provider := ""
+    switch {
+    case req.Polyline != "":
+        decoded, err := maps.DecodePolyline(req.Polyline)
+        if err != nil || len(decoded) == 0 {
+            c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid encoded polyline"})
+            return
+        }
+        points = decoded
+    case req.OriginLat != nil && req.OriginLng != nil && req.DestinationLat != nil && req.DestinationLng != nil:
+        route, err := h.mapService.GetRoute(
+            c.Request.Context(),
+            maps.LatLng{Lat: *req.OriginLat, Lng: *req.OriginLng},
+            maps.LatLng{Lat: *req.DestinationLat, Lng: *req.DestinationLng},
+            services.TravelOptions{},
+        )
+        if err != nil {
+            log.Printf("Koridor için rota alınamadı: %v", err)
+            c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+            return
+        }
+        points = route.Polyline.Points
+        provider = route.Provider
+    default:
+        c.JSON(http.StatusBadRequest, gin.H{"error": "Either polyline or origin and destination coordinates are required"})
+        return
+    }
+
+    stations := h.stationService.GetStationsAlongRoute(points, req.RadiusKM)
+    c.JSON(http.StatusOK, gin.H{
+        "stations":  stations,
+        "total":     len(stations),
+        "radius_km": req.RadiusKM,
+        "provider":  provider,
+    })
+}
+
+// PlanRoute, araç menzili ve şarj seviyesine göre rota üzerinde şarj durakları planlar
+func (h *StationHandler) PlanRoute(c *gin.Context) {
+    var req struct {
+        models.RouteRequest
+        RangeKM     float64  `json:"range_km" binding:"required"`
+        SoCPercent  float64  `json:"soc_percent" binding:"required"`
+        BatteryKWh  float64  `json:"battery_kwh"`
+        Connectors  []string `json:"connectors"`
+        MaxChargeKW float64  `json:"max_charge_kw"`
+        ReserveSoC  float64  `json:"reserve_soc_percent"`
+        TargetSoC   float64  `json:"target_soc_percent"`
+        CorridorKM  float64  `json:"corridor_km"`
+    }
+    if err := c.ShouldBindJSON(&req); err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+        return
+    }
+
+    planReq := services.ChargePlanRequest{
+        Origin:      maps.LatLng{Lat: req.OriginLat, Lng: req.OriginLng},
+        Destination: maps.LatLng{Lat: req.DestinationLat, Lng: req.DestinationLng},
+        RangeKM:     req.RangeKM,
+        SoCPercent:  req.SoCPercent,
+        BatteryKWh:  req.BatteryKWh,
+        Connectors:  req.Connectors,
+        MaxChargeKW: req.MaxChargeKW,
+        ReserveSoC:  req.ReserveSoC,
+        TargetSoC:   req.TargetSoC,
+        CorridorKM:  req.CorridorKM,
+    }
+    if err := planReq.Validate(); err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+        return
+    }
+
+    plan, err := h.routePlanner.Plan(c.Request.Context(), planReq)
+    if errors.Is(err, services.ErrNoReachableStation) {
+        c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+        return
+    }
+    if err != nil {
+        log.Printf("Rota planlanamadı: %v", err)
+        c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+        return
+    }
+
+    c.JSON(http.StatusOK, plan)
+}
+
+// CalculateDistance, iki nokta arası sürüş mesafesini kaynağı (google, osrm,
+// cache, estimate), yukarı akış durumu ve güven değeriyle döndürür.
+// strict true ise tahmin yerine 502 döner. departure_time verilirse trafikli
+// süre de (duration_in_traffic) döner.
 func (h *StationHandler) CalculateDistance(c *gin.Context) {
     var req struct {
-        Lat1 float64 `json:"lat1" binding:"required"`
-        Lon1 float64 `json:"lon1" binding:"required"`
-        Lat2 float64 `json:"lat2" binding:"required"`
-        Lon2 float64 `json:"lon2" binding:"required"`
+        Lat1   float64 `json:"lat1" binding:"required"`
+        Lon1   float64 `json:"lon1" binding:"required"`
+        Lat2   float64 `json:"lat2" binding:"required"`
+        Lon2   float64 `json:"lon2" binding:"required"`
+        Strict bool    `json:"strict"`
+        travelParams
     }
 
     if err := c.ShouldBindJSON(&req); err != nil {
@@ -123,11 +567,28 @@ func (h *StationHandler) CalculateDistance(c *gin.Context) {
 
     log.Printf("Received distance calculation request: %+v", req)
 
+    opts, err := req.options()
+    if err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+        return
+    }
+
     result, err := h.mapService.GetDistance(
+        c.Request.Context(),
         maps.LatLng{Lat: req.Lat1, Lng: req.Lon1},
         maps.LatLng{Lat: req.Lat2, Lng: req.Lon2},
+        opts,
+        req.Strict || c.Query("strict") == "true",
     )
 
+    var noRoad *services.NoRoadDistanceError
+    if errors.As(err, &noRoad) {
+        c.JSON(http.StatusBadGateway, gin.H{
+            "error":    noRoad.Error(),
+            "attempts": noRoad.Attempts,
+        })
+        return
+    }
     if err != nil {
         log.Printf("Distance calculation failed: %v", err)
         c.JSON(http.StatusInternalServerError, gin.H{
@@ -136,8 +597,76 @@ func (h *StationHandler) CalculateDistance(c *gin.Context) {
         return
     }
 
+    c.JSON(http.StatusOK, result)
+}
+
+// matrixPoint, mesafe matrisinde bir başlangıç ya da varış noktasıdır.
+// Varışlar istasyon ID'si ya da koordinatla verilebilir.
+type matrixPoint struct {
+    StationID string   `json:"station_id,omitempty"`
+    Name      string   `json:"name,omitempty"`
+    Lat       *float64 `json:"lat" binding:"omitempty,min=-90,max=90"`
+    Lng       *float64 `json:"lng" binding:"omitempty,min=-180,max=180"`
+}
+
+// GetDistanceMatrix, N başlangıçtan M varışa mesafe matrisini tek istekte döndürür.
+// Her hücre, yol mesafesi mi (road) yoksa kuş uçuşu tahmin mi (straight_line) olduğunu belirtir.
+func (h *StationHandler) GetDistanceMatrix(c *gin.Context) {
+    var req struct {
+        Origins      []matrixPoint `json:"origins" binding:"required,min=1,dive"`
+        Destinations []matrixPoint `json:"destinations" binding:"required,min=1,dive"`
+    }
+    if err := c.ShouldBindJSON(&req); err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+        return
+    }
+    if len(req.Origins) > maxMatrixOrigins || len(req.Destinations) > maxMatrixDestinations {
+        c.JSON(http.StatusBadRequest, gin.H{
+            "error": fmt.Sprintf("At most %d origins and %d destinations are allowed", maxMatrixOrigins, maxMatrixDestinations),
+        })
+        return
+    }
+
+    origins := make([]maps.LatLng, len(req.Origins))
+    for i := range req.Origins {
+        point, err := h.resolveMatrixPoint(&req.Origins[i])
+        if err != nil {
+            c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("origins[%d]: %v", i, err)})
+            return
+        }
+        origins[i] = point
+    }
+    destinations := make([]maps.LatLng, len(req.Destinations))
+    for i := range req.Destinations {
+        point, err := h.resolveMatrixPoint(&req.Destinations[i])
+        if err != nil {
+            c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("destinations[%d]: %v", i, err)})
+            return
+        }
+        destinations[i] = point
+    }
+
     c.JSON(http.StatusOK, gin.H{
-        "distance": result.Distance,
-        "duration": result.Duration,
+        "origins":      req.Origins,
+        "destinations": req.Destinations,
+        "rows":         h.mapService.GetDistanceMatrix(c.Request.Context(), origins, destinations),
     })
-} 
\ No newline at end of file
+}
+
+// resolveMatrixPoint, istasyon ID'sini katalogdaki koordinata çevirir ve
+// yanıtta görünmesi için noktayı istasyon bilgisiyle tamamlar
+func (h *StationHandler) resolveMatrixPoint(p *matrixPoint) (maps.LatLng, error) {
+    if p.StationID != "" {
+        station := h.stationService.GetStation(p.StationID)
+        if station == nil {
+            return maps.LatLng{}, fmt.Errorf("station %s not found", p.StationID)
+        }
+        p.StationID, p.Name = station.Key, station.Name
+        p.Lat, p.Lng = &station.Latitude, &station.Longitude
+        return maps.LatLng{Lat: station.Latitude, Lng: station.Longitude}, nil
+    }
+    if p.Lat == nil || p.Lng == nil {
+        return maps.LatLng{}, fmt.Errorf("station_id or lat/lng is required")
+    }
+    return maps.LatLng{Lat: *p.Lat, Lng: *p.Lng}, nil
+}
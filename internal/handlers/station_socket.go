@@ -0,0 +1,251 @@
+package handlers
+
+import (
+    "charging-stations-backend/internal/services"
+    "encoding/json"
+    "fmt"
+    "log"
+    "net/http"
+    "sync"
+    "time"
+
+    "github.com/gin-gonic/gin"
+    "github.com/gorilla/websocket"
+)
+
+const (
+    socketMaxSubscriptions = 100
+    socketSendBuffer       = 64
+    socketEventBuffer      = 256
+    socketMaxMessageSize   = 4096
+    socketWriteWait        = 10 * time.Second
+    socketPongWait         = 60 * time.Second
+    socketPingPeriod       = socketPongWait * 9 / 10
+)
+
+var socketUpgrader = websocket.Upgrader{
+    ReadBufferSize:  1024,
+    WriteBufferSize: 1024,
+    // CORS her kaynağa açık, WebSocket için de aynı politika
+    CheckOrigin: func(r *http.Request) bool { return true },
+}
+
+// İstemciden gelen mesaj:
+// {"action":"subscribe","station_ids":["trugo:1"],"bbox":[28.9,40.9,29.1,41.1]}
+type socketRequest struct {
+    Action     string    `json:"action"`
+    StationIDs []string  `json:"station_ids"`
+    BBox       []float64 `json:"bbox"`
+}
+
+// stationSocket, tek bir WebSocket bağlantısının abonelik durumudur
+type stationSocket struct {
+    conn *websocket.Conn
+    send chan []byte
+    done chan struct{}
+    once sync.Once
+
+    mu      sync.RWMutex
+    keys    map[string]bool
+    regions map[[4]float64]bool
+}
+
+func (s *stationSocket) match(event services.StationEvent) bool {
+    s.mu.RLock()
+    defer s.mu.RUnlock()
+
+    if s.keys[event.StationKey] {
+        return true
+    }
+    if event.Station != nil {
+        for bbox := range s.regions {
+            if inBBox(bbox, event.Station.Latitude, event.Station.Longitude) {
+                return true
+            }
+        }
+    }
+    return false
+}
+
+// enqueue, mesajı gönderim kuyruğuna ekler. Kuyruk doluysa istemci yavaş
+// kabul edilir ve bağlantı kapatılır; sunucu onun için bellek biriktirmez.
+func (s *stationSocket) enqueue(msg []byte) bool {
+    select {
+    case s.send <- msg:
+        return true
+    case <-s.done:
+        return false
+    default:
+        log.Printf("WebSocket istemcisi yavaş, bağlantı kapatılıyor")
+        s.close(websocket.ClosePolicyViolation, "slow consumer")
+        return false
+    }
+}
+
+func (s *stationSocket) enqueueJSON(v interface{}) bool {
+    msg, err := json.Marshal(v)
+    if err != nil {
+        log.Printf("WebSocket mesajı oluşturulamadı: %v", err)
+        return false
+    }
+    return s.enqueue(msg)
+}
+
+func (s *stationSocket) close(code int, reason string) {
+    s.once.Do(func() {
+        close(s.done)
+        deadline := time.Now().Add(socketWriteWait)
+        s.conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(code, reason), deadline)
+        s.conn.Close()
+    })
+}
+
+// StationSocket, istemcinin çalışma anında istasyon ve bölge aboneliği
+// ekleyip çıkarabildiği WebSocket bağlantısıdır. Abone olunan istasyonlar
+// için soket durumu, yeni yorum ve puan değişikliği olayları gönderilir.
+func (h *StationHandler) StationSocket(c *gin.Context) {
+    conn, err := socketUpgrader.Upgrade(c.Writer, c.Request, nil)
+    if err != nil {
+        log.Printf("WebSocket yükseltme hatası: %v", err)
+        return
+    }
+
+    socket := &stationSocket{
+        conn:    conn,
+        send:    make(chan []byte, socketSendBuffer),
+        done:    make(chan struct{}),
+        keys:    make(map[string]bool),
+        regions: make(map[[4]float64]bool),
+    }
+
+    sub := h.stationService.Events().Subscribe(socketEventBuffer)
+    defer sub.Close()
+
+    go socket.writePump()
+    go func() {
+        for {
+            select {
+            case <-socket.done:
+                return
+            case event, ok := <-sub.C:
+                if !ok {
+                    return
+                }
+                if socket.match(event) && !socket.enqueueJSON(event) {
+                    return
+                }
+            }
+        }
+    }()
+
+    h.socketReadPump(socket)
+}
+
+func (h *StationHandler) socketReadPump(socket *stationSocket) {
+    defer socket.close(websocket.CloseNormalClosure, "")
+
+    socket.conn.SetReadLimit(socketMaxMessageSize)
+    socket.conn.SetReadDeadline(time.Now().Add(socketPongWait))
+    socket.conn.SetPongHandler(func(string) error {
+        return socket.conn.SetReadDeadline(time.Now().Add(socketPongWait))
+    })
+
+    for {
+        var req socketRequest
+        if err := socket.conn.ReadJSON(&req); err != nil {
+            if websocket.IsUnexpectedCloseError(err, websocket.CloseNormalClosure, websocket.CloseGoingAway) {
+                log.Printf("WebSocket okuma hatası: %v", err)
+            }
+            return
+        }
+
+        reply, err := h.applySocketRequest(socket, req)
+        if err != nil {
+            reply = gin.H{"type": "error", "error": err.Error()}
+        }
+        if !socket.enqueueJSON(reply) {
+            return
+        }
+    }
+}
+
+func (h *StationHandler) applySocketRequest(socket *stationSocket, req socketRequest) (gin.H, error) {
+    var region *[4]float64
+    if len(req.BBox) > 0 {
+        if len(req.BBox) != 4 {
+            return nil, fmt.Errorf("Invalid bbox, expected [minLng,minLat,maxLng,maxLat]")
+        }
+        bbox := [4]float64{req.BBox[0], req.BBox[1], req.BBox[2], req.BBox[3]}
+        if err := validateBBox(bbox); err != nil {
+            return nil, err
+        }
+        region = &bbox
+    }
+
+    socket.mu.Lock()
+    defer socket.mu.Unlock()
+
+    switch req.Action {
+    case "subscribe":
+        added := len(req.StationIDs)
+        if region != nil {
+            added++
+        }
+        if len(socket.keys)+len(socket.regions)+added > socketMaxSubscriptions {
+            return nil, fmt.Errorf("Subscription limit reached (%d)", socketMaxSubscriptions)
+        }
+        for _, id := range req.StationIDs {
+            socket.keys[h.stationService.ResolveKey(id)] = true
+        }
+        if region != nil {
+            socket.regions[*region] = true
+        }
+    case "unsubscribe":
+        for _, id := range req.StationIDs {
+            delete(socket.keys, h.stationService.ResolveKey(id))
+        }
+        if region != nil {
+            delete(socket.regions, *region)
+        }
+    case "ping":
+        return gin.H{"type": "pong", "time": time.Now()}, nil
+    default:
+        return nil, fmt.Errorf("Unknown action: %s", req.Action)
+    }
+
+    keys := make([]string, 0, len(socket.keys))
+    for key := range socket.keys {
+        keys = append(keys, key)
+    }
+    regions := make([][4]float64, 0, len(socket.regions))
+    for bbox := range socket.regions {
+        regions = append(regions, bbox)
+    }
+    return gin.H{"type": "subscriptions", "station_ids": keys, "regions": regions}, nil
+}
+
+// writePump, bağlantıya yazan tek goroutine'dir; kuyruğu boşaltır ve
+// düzenli ping gönderir
+func (s *stationSocket) writePump() {
+    ticker := time.NewTicker(socketPingPeriod)
+    defer ticker.Stop()
+
+    for {
+        select {
+        case <-s.done:
+            return
+        case msg := <-s.send:
+            s.conn.SetWriteDeadline(time.Now().Add(socketWriteWait))
+            if err := s.conn.WriteMessage(websocket.TextMessage, msg); err != nil {
+                s.close(websocket.CloseGoingAway, "")
+                return
+            }
+        case <-ticker.C:
+            s.conn.SetWriteDeadline(time.Now().Add(socketWriteWait))
+            if err := s.conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+                s.close(websocket.CloseGoingAway, "")
+                return
+            }
+        }
+    }
+}
@@ -0,0 +1,141 @@
+package handlers
+
+import (
+    "charging-stations-backend/internal/services"
+    "fmt"
+    "net/http"
+    "strconv"
+    "strings"
+    "time"
+
+    "github.com/gin-gonic/gin"
+)
+
+const (
+    streamBufferSize = 64
+    streamHeartbeat  = 15 * time.Second
+)
+
+// stationEventFilter, istasyon anahtarına ya da kutuya göre olay süzer.
+// İkisi de boşsa tüm olaylar geçer.
+type stationEventFilter struct {
+    keys map[string]bool
+    bbox *[4]float64 // minLng, minLat, maxLng, maxLat
+}
+
+func (f stationEventFilter) match(event services.StationEvent) bool {
+    if len(f.keys) == 0 && f.bbox == nil {
+        return true
+    }
+    if f.keys[event.StationKey] {
+        return true
+    }
+    if f.bbox != nil && event.Station != nil {
+        return inBBox(*f.bbox, event.Station.Latitude, event.Station.Longitude)
+    }
+    return false
+}
+
+func inBBox(bbox [4]float64, lat, lon float64) bool {
+    if lat < bbox[1] || lat > bbox[3] {
+        return false
+    }
+    if bbox[0] > bbox[2] {
+        return lon >= bbox[0] || lon <= bbox[2]
+    }
+    return lon >= bbox[0] && lon <= bbox[2]
+}
+
+// parseBBox, "minLng,minLat,maxLng,maxLat" biçimini çözer
+func parseBBox(value string) ([4]float64, error) {
+    var bbox [4]float64
+    parts := strings.Split(value, ",")
+    if len(parts) != 4 {
+        return bbox, fmt.Errorf("Invalid bbox, expected minLng,minLat,maxLng,maxLat")
+    }
+    for i, p := range parts {
+        v, err := strconv.ParseFloat(strings.TrimSpace(p), 64)
+        if err != nil {
+            return bbox, fmt.Errorf("Invalid bbox, expected minLng,minLat,maxLng,maxLat")
+        }
+        bbox[i] = v
+    }
+    return bbox, validateBBox(bbox)
+}
+
+// validateBBox, enlem ve boylam aralıklarını denetler. minLng > maxLng,
+// 180. meridyeni aşan kutu anlamına gelir.
+func validateBBox(bbox [4]float64) error {
+    if bbox[1] > bbox[3] || bbox[1] < -90 || bbox[3] > 90 {
+        return fmt.Errorf("Invalid bbox latitude range")
+    }
+    if bbox[0] < -180 || bbox[0] > 180 || bbox[2] < -180 || bbox[2] > 180 {
+        return fmt.Errorf("Invalid bbox longitude range")
+    }
+    return nil
+}
+
+// StreamStations, katalog yenilemelerinde soket durumu değişen istasyonları
+// Server-Sent Events olarak yayınlar. ids=trugo:1,trugo:2 ya da
+// bbox=minLng,minLat,maxLng,maxLat ile süzülebilir. İstemci yavaş kaldığı için
+// olay düşürülürse "resync" olayı gönderilir; istemci görünümünü yeniden yüklemelidir.
+func (h *StationHandler) StreamStations(c *gin.Context) {
+    var filter stationEventFilter
+    if ids := queryList(c, "ids"); len(ids) > 0 {
+        filter.keys = make(map[string]bool, len(ids))
+        for _, id := range ids {
+            filter.keys[h.stationService.ResolveKey(id)] = true
+        }
+    }
+    if v := c.Query("bbox"); v != "" {
+        bbox, err := parseBBox(v)
+        if err != nil {
+            c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+            return
+        }
+        filter.bbox = &bbox
+    }
+
+    sub := h.stationService.Events().Subscribe(streamBufferSize)
+    defer sub.Close()
+
+    c.Header("Content-Type", "text/event-stream")
+    c.Header("Cache-Control", "no-cache")
+    c.Header("Connection", "keep-alive")
+    c.Header("X-Accel-Buffering", "no") // nginx arkasında tamponlamayı kapat
+    c.Status(http.StatusOK)
+    c.SSEvent("ready", gin.H{"time": time.Now()})
+    c.Writer.Flush()
+
+    heartbeat := time.NewTicker(streamHeartbeat)
+    defer heartbeat.Stop()
+
+    var reportedDrops int64
+    for {
+        select {
+        case <-c.Request.Context().Done():
+            return
+        case <-heartbeat.C:
+            // Yorum satırı, proxy'lerin bağlantıyı boşta sanıp kapatmasını önler
+            if _, err := fmt.Fprint(c.Writer, ": ping\n\n"); err != nil {
+                return
+            }
+            c.Writer.Flush()
+        case event, ok := <-sub.C:
+            if !ok {
+                return
+            }
+            if dropped := sub.Dropped(); dropped > reportedDrops {
+                c.SSEvent("resync", gin.H{"dropped": dropped - reportedDrops, "time": time.Now()})
+                c.Writer.Flush()
+                reportedDrops = dropped
+            }
+            // SSE akışı yalnızca soket durumu değişikliklerini taşır
+            if event.Type != services.EventAvailabilityChanged || !filter.match(event) {
+                continue
+            }
+            c.SSEvent(event.Type, event)
+            c.Writer.Flush()
+        }
+    }
+}
@@ -0,0 +1,154 @@
+package handlers
+
+import (
+    "charging-stations-backend/internal/services"
+    "log"
+    "net/http"
+    "net/url"
+    "strconv"
+
+    "github.com/gin-gonic/gin"
+)
+
+const (
+    defaultDeliveriesLimit = 50
+    maxDeliveriesLimit     = 500
+)
+
+type WebhookHandler struct {
+    store *services.WebhookStore
+}
+
+func NewWebhookHandler(store *services.WebhookStore) *WebhookHandler {
+    return &WebhookHandler{store: store}
+}
+
+// CreateSubscription, yeni bir webhook aboneliği oluşturur. İmza anahtarı
+// yalnızca bu yanıtta döner.
+func (h *WebhookHandler) CreateSubscription(c *gin.Context) {
+    var req struct {
+        URL        string   `json:"url" binding:"required"`
+        EventTypes []string `json:"event_types" binding:"required,min=1"`
+    }
+    if err := c.ShouldBindJSON(&req); err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+        return
+    }
+
+    u, err := url.Parse(req.URL)
+    if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+        c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid url, expected http(s)://..."})
+        return
+    }
+    for _, t := range req.EventTypes {
+        if !containsString(services.WebhookEventTypes, t) {
+            c.JSON(http.StatusBadRequest, gin.H{
+                "error":       "Unknown event type: " + t,
+                "event_types": services.WebhookEventTypes,
+            })
+            return
+        }
+    }
+
+    sub, err := h.store.CreateSubscription(c.Request.Context(), req.URL, req.EventTypes)
+    if err != nil {
+        log.Printf("Webhook aboneliği oluşturulamadı: %v", err)
+        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create webhook"})
+        return
+    }
+    c.JSON(http.StatusCreated, sub)
+}
+
+func (h *WebhookHandler) ListSubscriptions(c *gin.Context) {
+    subs, err := h.store.ListSubscriptions(c.Request.Context())
+    if err != nil {
+        log.Printf("Webhook abonelikleri okunamadı: %v", err)
+        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list webhooks"})
+        return
+    }
+    c.JSON(http.StatusOK, subs)
+}
+
+func (h *WebhookHandler) DeleteSubscription(c *gin.Context) {
+    id, err := strconv.Atoi(c.Param("id"))
+    if err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid webhook id"})
+        return
+    }
+
+    found, err := h.store.DeleteSubscription(c.Request.Context(), id)
+    if err != nil {
+        log.Printf("Webhook aboneliği silinemedi: %v", err)
+        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete webhook"})
+        return
+    }
+    if !found {
+        c.JSON(http.StatusNotFound, gin.H{"error": "Webhook not found"})
+        return
+    }
+    c.Status(http.StatusNoContent)
+}
+
+// GetDeliveries, aboneliğin teslimat kayıtlarını döndürür (status ile süzülebilir)
+func (h *WebhookHandler) GetDeliveries(c *gin.Context) {
+    id, err := strconv.Atoi(c.Param("id"))
+    if err != nil || id <= 0 {
+        c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid webhook id"})
+        return
+    }
+    h.listDeliveries(c, id, c.Query("status"))
+}
+
+// GetDeadLetters, tüm denemeleri tükenmiş teslimatları döndürür
+func (h *WebhookHandler) GetDeadLetters(c *gin.Context) {
+    h.listDeliveries(c, 0, services.DeliveryDead)
+}
+
+// RetryDelivery, dead-letter listesindeki bir teslimatı yeniden kuyruğa alır
+func (h *WebhookHandler) RetryDelivery(c *gin.Context) {
+    id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+    if err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid delivery id"})
+        return
+    }
+
+    found, err := h.store.RetryDelivery(c.Request.Context(), id)
+    if err != nil {
+        log.Printf("Webhook teslimatı yeniden kuyruğa alınamadı: %v", err)
+        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retry delivery"})
+        return
+    }
+    if !found {
+        c.JSON(http.StatusNotFound, gin.H{"error": "Dead-lettered delivery not found"})
+        return
+    }
+    c.JSON(http.StatusAccepted, gin.H{"message": "Delivery requeued"})
+}
+
+func (h *WebhookHandler) listDeliveries(c *gin.Context, subscriptionID int, status string) {
+    limit, err := strconv.Atoi(c.DefaultQuery("limit", strconv.Itoa(defaultDeliveriesLimit)))
+    if err != nil || limit <= 0 {
+        c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid limit"})
+        return
+    }
+    if limit > maxDeliveriesLimit {
+        limit = maxDeliveriesLimit
+    }
+
+    deliveries, err := h.store.ListDeliveries(c.Request.Context(), subscriptionID, status, limit)
+    if err != nil {
+        log.Printf("Webhook teslimatları okunamadı: %v", err)
+        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list deliveries"})
+        return
+    }
+    c.JSON(http.StatusOK, deliveries)
+}
+
+func containsString(values []string, s string) bool {
+    for _, v := range values {
+        if v == s {
+            return true
+        }
+    }
+    return false
+}
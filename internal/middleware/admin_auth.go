@@ -0,0 +1,23 @@
+package middleware
+
+import (
+    "crypto/subtle"
+    "net/http"
+    "strings"
+
+    "github.com/gin-gonic/gin"
+)
+
+// BearerTokenAuth, yönetim endpoint'leri için "Authorization: Bearer <token>" başlığını doğrular
+func BearerTokenAuth(token string) gin.HandlerFunc {
+    return func(c *gin.Context) {
+        header := c.GetHeader("Authorization")
+        given := strings.TrimPrefix(header, "Bearer ")
+
+        if given == header || subtle.ConstantTimeCompare([]byte(given), []byte(token)) != 1 {
+            c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+            return
+        }
+        c.Next()
+    }
+}
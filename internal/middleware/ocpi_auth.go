@@ -0,0 +1,57 @@
+package middleware
+
+import (
+    "charging-stations-backend/internal/models"
+    "crypto/subtle"
+    "encoding/base64"
+    "net/http"
+    "strings"
+    "time"
+
+    "github.com/gin-gonic/gin"
+)
+
+// OCPITokenAuth, "Authorization: Token <token>" başlığını doğrular.
+// OCPI 2.2.1 token'ı base64 ile kodlar; eski sürümlerle uyum için düz metin de kabul edilir.
+func OCPITokenAuth(tokens []string) gin.HandlerFunc {
+    return func(c *gin.Context) {
+        header := c.GetHeader("Authorization")
+        token := strings.TrimSpace(strings.TrimPrefix(header, "Token "))
+
+        if header == token || !validOCPIToken(tokens, token) {
+            c.AbortWithStatusJSON(http.StatusUnauthorized, models.OCPIResponse{
+                StatusCode:    models.OCPIStatusClientError,
+                StatusMessage: "Invalid or missing token",
+                Timestamp:     time.Now().UTC(),
+            })
+            return
+        }
+        c.Next()
+    }
+}
+
+// validOCPIToken, verilen token'ı tüm tanımlı token'larla sabit sürede karşılaştırır
+func validOCPIToken(tokens []string, given string) bool {
+    decoded := decodeOCPIToken(given)
+    valid := false
+    for _, t := range tokens {
+        if t == "" {
+            continue
+        }
+        if subtle.ConstantTimeCompare([]byte(given), []byte(t)) == 1 {
+            valid = true
+        }
+        if subtle.ConstantTimeCompare([]byte(decoded), []byte(t)) == 1 {
+            valid = true
+        }
+    }
+    return valid
+}
+
+func decodeOCPIToken(token string) string {
+    decoded, err := base64.StdEncoding.DecodeString(token)
+    if err != nil {
+        return ""
+    }
+    return string(decoded)
+}
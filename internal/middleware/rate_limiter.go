@@ -50,4 +50,16 @@ func RateLimitMiddleware(limiter *IPRateLimiter) gin.HandlerFunc {
         }
         c.Next()
     }
-} 
\ No newline at end of file
+} 
+// RateLimitWhen, limiti yalnızca when true dönen isteklere uygular. Aynı
+// endpoint'in ücretli harita API'sini çağıran kipleri böyle sınırlanır.
+func RateLimitWhen(limiter *IPRateLimiter, when func(c *gin.Context) bool) gin.HandlerFunc {
+    limit := RateLimitMiddleware(limiter)
+    return func(c *gin.Context) {
+        if when(c) {
+            limit(c)
+            return
+        }
+        c.Next()
+    }
+}
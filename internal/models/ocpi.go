@@ -0,0 +1,84 @@
+package models
+
+import (
+    "encoding/json"
+    "time"
+)
+
+// OCPI 2.2.1 yanıt zarfı
+type OCPIResponse struct {
+    Data          json.RawMessage `json:"data,omitempty"`
+    StatusCode    int             `json:"status_code"`
+    StatusMessage string          `json:"status_message,omitempty"`
+    Timestamp     time.Time       `json:"timestamp"`
+}
+
+// OCPI durum kodları
+const (
+    OCPIStatusSuccess       = 1000
+    OCPIStatusClientError   = 2000
+    OCPIStatusUnknownObject = 2003
+    OCPIStatusServerError   = 3000
+)
+
+// OCPI 2.2.1 Locations modülü nesneleri
+type OCPILocation struct {
+    CountryCode string               `json:"country_code"`
+    PartyID     string               `json:"party_id"`
+    ID          string               `json:"id"`
+    Publish     *bool                `json:"publish,omitempty"`
+    Name        string               `json:"name,omitempty"`
+    Address     string               `json:"address"`
+    City        string               `json:"city"`
+    PostalCode  string               `json:"postal_code,omitempty"`
+    Country     string               `json:"country"`
+    Coordinates OCPIGeoLocation      `json:"coordinates"`
+    EVSEs       []OCPIEVSE           `json:"evses,omitempty"`
+    Operator    *OCPIBusinessDetails `json:"operator,omitempty"`
+    TimeZone    string               `json:"time_zone"`
+    LastUpdated time.Time            `json:"last_updated"`
+}
+
+// OCPI koordinatları metin olarak taşır (ör. "41.0082")
+type OCPIGeoLocation struct {
+    Latitude  string `json:"latitude"`
+    Longitude string `json:"longitude"`
+}
+
+type OCPIBusinessDetails struct {
+    Name    string `json:"name"`
+    Website string `json:"website,omitempty"`
+}
+
+type OCPIEVSE struct {
+    UID         string           `json:"uid"`
+    EVSEID      string           `json:"evse_id,omitempty"`
+    Status      string           `json:"status"`
+    Connectors  []OCPIConnector  `json:"connectors"`
+    Coordinates *OCPIGeoLocation `json:"coordinates,omitempty"`
+    LastUpdated time.Time        `json:"last_updated"`
+}
+
+type OCPIConnector struct {
+    ID               string    `json:"id"`
+    Standard         string    `json:"standard"`
+    Format           string    `json:"format"`
+    PowerType        string    `json:"power_type"`
+    MaxVoltage       int       `json:"max_voltage"`
+    MaxAmperage      int       `json:"max_amperage"`
+    MaxElectricPower int       `json:"max_electric_power,omitempty"`
+    LastUpdated      time.Time `json:"last_updated"`
+}
+
+// OCPI EVSE durumları
+const (
+    OCPIEVSEAvailable   = "AVAILABLE"
+    OCPIEVSEBlocked     = "BLOCKED"
+    OCPIEVSECharging    = "CHARGING"
+    OCPIEVSEInoperative = "INOPERATIVE"
+    OCPIEVSEOutOfOrder  = "OUTOFORDER"
+    OCPIEVSEPlanned     = "PLANNED"
+    OCPIEVSERemoved     = "REMOVED"
+    OCPIEVSEReserved    = "RESERVED"
+    OCPIEVSEUnknown     = "UNKNOWN"
+)
@@ -7,7 +7,8 @@ type Station struct {
     Brand                  string  `json:"brand"`
     Latitude               float64 `json:"latitude"`
     Longitude              float64 `json:"longitude"`
-    ConnectorList          string  `json:"connector_list"`
+    ConnectorList          string  `json:"connector_list"` // ham değer, hata ayıklama için saklanır
+    Connectors             []Connector `json:"connectors"`
     ErrorDeviceCount       int     `json:"error_device_count"`
     ACAvailableSocketCount int     `json:"ac_available_sockets_count"`
     DCAvailableSocketCount int     `json:"dc_available_sockets_count"`
@@ -18,6 +19,34 @@ type Station struct {
     ReviewCount            int     `json:"review_count"`
 }
 
+// Bir istasyondaki aynı tip ve güçteki soketlerin özeti
+type Connector struct {
+    Standard   string  `json:"standard"`   // CCS2, CHAdeMO, Type2, ...
+    PowerType  string  `json:"power_type"` // AC ya da DC
+    MaxPowerKW float64 `json:"max_power_kw,omitempty"`
+    Count      int     `json:"count"`
+}
+
+// Soket standartları
+const (
+    ConnectorCCS1    = "CCS1"
+    ConnectorCCS2    = "CCS2"
+    ConnectorCHAdeMO = "CHAdeMO"
+    ConnectorType1   = "Type1"
+    ConnectorType2   = "Type2"
+    ConnectorGBTAC   = "GB/T AC"
+    ConnectorGBTDC   = "GB/T DC"
+    ConnectorTesla   = "Tesla"
+    ConnectorSchuko  = "Schuko"
+    ConnectorUnknown = "Unknown"
+)
+
+// Güç tipleri
+const (
+    PowerTypeAC = "AC"
+    PowerTypeDC = "DC"
+)
+
 type StationStats struct {
     AverageRating float64 `json:"average_rating"`
     ReviewCount   int     `json:"review_count"`
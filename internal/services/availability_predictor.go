@@ -0,0 +1,134 @@
+package services
+
+import (
+    "context"
+    "time"
+)
+
+const (
+    // Tahminde kullanılan geçmiş
+    predictionLookback = 8 * 7 * 24 * time.Hour
+    // Saat dilimindeki az örneği istasyon ortalamasına çeken sanal örnek sayısı
+    predictionPriorWeight = 10.0
+    // Hiç veri yokken kullanılan ön olasılık
+    predictionDefaultPrior = 0.5
+)
+
+// Bir hafta günü/saat dilimi için boş soket bulma olasılığı
+type AvailabilityPrediction struct {
+    Weekday    int      `json:"weekday"` // 0 = Pazar
+    Hour       int      `json:"hour"`
+    ACFreeProb *float64 `json:"ac_free_probability,omitempty"`
+    DCFreeProb *float64 `json:"dc_free_probability,omitempty"`
+    Samples    int      `json:"samples"`
+    Confidence float64  `json:"confidence"` // 0-1, örnek sayısı arttıkça 1'e yaklaşır
+}
+
+// AvailabilityPredictor, soket geçmişinden "genelde dolu" tahmini üretir.
+// Model basittir: her hafta günü/saat için boş soket görülen örneklerin oranı,
+// istasyonun genel oranına doğru Beta önseliyle yumuşatılır. Böylece az
+// örnekli saatler uç değerlere (0 ya da 1) kaymaz.
+type AvailabilityPredictor struct {
+    store    *AvailabilityStore
+    location *time.Location
+}
+
+func NewAvailabilityPredictor(store *AvailabilityStore, location *time.Location) *AvailabilityPredictor {
+    if location == nil {
+        location = time.UTC
+    }
+    return &AvailabilityPredictor{store: store, location: location}
+}
+
+func (p *AvailabilityPredictor) Location() *time.Location {
+    return p.location
+}
+
+type slotCounts struct {
+    samples int
+    acFree  int
+    dcFree  int
+}
+
+// WeeklyProfile, 7x24 tahmin tablosunu döndürür (Pazar 00:00'dan başlayarak)
+func (p *AvailabilityPredictor) WeeklyProfile(ctx context.Context, stationID string, hasAC, hasDC bool) ([]AvailabilityPrediction, error) {
+    slots, err := p.slotCounts(ctx, stationID)
+    if err != nil {
+        return nil, err
+    }
+
+    var total slotCounts
+    for _, s := range slots {
+        total.samples += s.samples
+        total.acFree += s.acFree
+        total.dcFree += s.dcFree
+    }
+    acPrior := smoothedRatio(total.acFree, total.samples, predictionDefaultPrior, 1)
+    dcPrior := smoothedRatio(total.dcFree, total.samples, predictionDefaultPrior, 1)
+
+    predictions := make([]AvailabilityPrediction, 0, 7*24)
+    for weekday := 0; weekday < 7; weekday++ {
+        for hour := 0; hour < 24; hour++ {
+            s := slots[[2]int{weekday, hour}]
+            prediction := AvailabilityPrediction{
+                Weekday:    weekday,
+                Hour:       hour,
+                Samples:    s.samples,
+                Confidence: float64(s.samples) / (float64(s.samples) + predictionPriorWeight),
+            }
+            if hasAC {
+                v := smoothedRatio(s.acFree, s.samples, acPrior, predictionPriorWeight)
+                prediction.ACFreeProb = &v
+            }
+            if hasDC {
+                v := smoothedRatio(s.dcFree, s.samples, dcPrior, predictionPriorWeight)
+                prediction.DCFreeProb = &v
+            }
+            predictions = append(predictions, prediction)
+        }
+    }
+    return predictions, nil
+}
+
+// Predict, tek bir hafta günü ve saat için tahmini döndürür
+func (p *AvailabilityPredictor) Predict(ctx context.Context, stationID string, weekday, hour int, hasAC, hasDC bool) (AvailabilityPrediction, error) {
+    profile, err := p.WeeklyProfile(ctx, stationID, hasAC, hasDC)
+    if err != nil {
+        return AvailabilityPrediction{}, err
+    }
+    return profile[weekday*24+hour], nil
+}
+
+func (p *AvailabilityPredictor) slotCounts(ctx context.Context, stationID string) (map[[2]int]slotCounts, error) {
+    query := `
+        SELECT EXTRACT(DOW FROM recorded_at AT TIME ZONE $2)::int AS weekday,
+               EXTRACT(HOUR FROM recorded_at AT TIME ZONE $2)::int AS hour,
+               COUNT(*),
+               SUM(CASE WHEN ac_available > 0 THEN 1 ELSE 0 END),
+               SUM(CASE WHEN dc_available > 0 THEN 1 ELSE 0 END)
+        FROM station_availability
+        WHERE station_id = $1 AND recorded_at >= $3
+        GROUP BY weekday, hour`
+
+    rows, err := p.store.db.QueryContext(ctx, query, stationID, p.location.String(), time.Now().Add(-predictionLookback))
+    if err != nil {
+        return nil, err
+    }
+    defer rows.Close()
+
+    slots := make(map[[2]int]slotCounts)
+    for rows.Next() {
+        var weekday, hour int
+        var s slotCounts
+        if err := rows.Scan(&weekday, &hour, &s.samples, &s.acFree, &s.dcFree); err != nil {
+            return nil, err
+        }
+        slots[[2]int{weekday, hour}] = s
+    }
+    return slots, rows.Err()
+}
+
+// smoothedRatio, (başarı + ağırlık*önsel) / (deneme + ağırlık)
+func smoothedRatio(hits, samples int, prior, weight float64) float64 {
+    return (float64(hits) + weight*prior) / (float64(samples) + weight)
+}
@@ -0,0 +1,151 @@
+package services
+
+import (
+    "context"
+    "database/sql"
+    "fmt"
+    "log"
+    "sync"
+    "time"
+
+    "github.com/lib/pq"
+)
+
+// Varsayılan saklama süresi, AVAILABILITY_RETENTION ile değiştirilebilir
+const defaultAvailabilityRetention = 90 * 24 * time.Hour
+
+// AvailabilityStore, her katalog yenilemesindeki soket sayaçlarını
+// station_availability tablosunda zaman serisi olarak saklar
+type AvailabilityStore struct {
+    db        *sql.DB
+    retention time.Duration
+
+    mu        sync.Mutex
+    lastPrune time.Time
+}
+
+// Bir zaman aralığındaki örneklerin özeti
+type AvailabilityBucket struct {
+    Start          time.Time `json:"start"`
+    Samples        int       `json:"samples"`
+    AvgACAvailable float64   `json:"avg_ac_available"`
+    AvgDCAvailable float64   `json:"avg_dc_available"`
+    ACFreeRatio    float64   `json:"ac_free_ratio"` // en az bir AC soketin boş olduğu örneklerin oranı
+    DCFreeRatio    float64   `json:"dc_free_ratio"`
+    Occupancy      *float64  `json:"occupancy,omitempty"` // çalışan soketlerin ortalama doluluk oranı
+}
+
+func NewAvailabilityStore(db *sql.DB, retention time.Duration) *AvailabilityStore {
+    if retention <= 0 {
+        retention = defaultAvailabilityRetention
+    }
+    return &AvailabilityStore{db: db, retention: retention}
+}
+
+// Record, istasyonların o anki sayaçlarını tek bir COPY ile yazar
+func (s *AvailabilityStore) Record(ctx context.Context, stations []Station, at time.Time) error {
+    tx, err := s.db.BeginTx(ctx, nil)
+    if err != nil {
+        return fmt.Errorf("transaction başlatılamadı: %v", err)
+    }
+    defer tx.Rollback()
+
+    stmt, err := tx.Prepare(pq.CopyIn("station_availability",
+        "station_id", "ac_available", "dc_available", "error_count",
+        "unavailable_count", "total_connectors", "recorded_at"))
+    if err != nil {
+        return fmt.Errorf("COPY hazırlanamadı: %v", err)
+    }
+
+    for _, st := range stations {
+        if _, err := stmt.Exec(st.Key, st.ACAvailableSocketCount, st.DCAvailableSocketCount,
+            st.ErrorDeviceCount, st.UnavailableDeviceCount, st.TotalConnectorsCount, at); err != nil {
+            stmt.Close()
+            return fmt.Errorf("örnek yazılamadı: %v", err)
+        }
+    }
+    if _, err := stmt.Exec(); err != nil {
+        stmt.Close()
+        return fmt.Errorf("COPY tamamlanamadı: %v", err)
+    }
+    if err := stmt.Close(); err != nil {
+        return err
+    }
+    if err := tx.Commit(); err != nil {
+        return err
+    }
+
+    s.pruneIfDue(ctx, at)
+    return nil
+}
+
+// RecordRefresh, StationService.OnRefresh'e verilecek dinleyicidir
+func (s *AvailabilityStore) RecordRefresh(stations []Station, at time.Time) {
+    ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+    defer cancel()
+
+    if err := s.Record(ctx, stations, at); err != nil {
+        log.Printf("Soket geçmişi kaydedilemedi: %v", err)
+    }
+}
+
+// pruneIfDue, günde en fazla bir kez saklama süresini aşan örnekleri siler
+func (s *AvailabilityStore) pruneIfDue(ctx context.Context, now time.Time) {
+    s.mu.Lock()
+    if now.Sub(s.lastPrune) < 24*time.Hour {
+        s.mu.Unlock()
+        return
+    }
+    s.lastPrune = now
+    s.mu.Unlock()
+
+    res, err := s.db.ExecContext(ctx, `DELETE FROM station_availability WHERE recorded_at < $1`, now.Add(-s.retention))
+    if err != nil {
+        log.Printf("Eski soket geçmişi silinemedi: %v", err)
+        return
+    }
+    if n, _ := res.RowsAffected(); n > 0 {
+        log.Printf("%d eski soket geçmişi kaydı silindi", n)
+    }
+}
+
+// History, istasyonun [from, to) aralığındaki örneklerini bucket süresinde gruplar
+func (s *AvailabilityStore) History(ctx context.Context, stationID string, from, to time.Time, bucket time.Duration) ([]AvailabilityBucket, error) {
+    query := `
+        SELECT to_timestamp(floor(extract(epoch FROM recorded_at)::float8 / $4::float8) * $4::float8) AS bucket_start,
+               COUNT(*),
+               AVG(ac_available),
+               AVG(dc_available),
+               AVG(CASE WHEN ac_available > 0 THEN 1 ELSE 0 END),
+               AVG(CASE WHEN dc_available > 0 THEN 1 ELSE 0 END),
+               AVG(CASE WHEN total_connectors - error_count - unavailable_count > 0
+                        THEN 1 - LEAST(ac_available + dc_available, total_connectors - error_count - unavailable_count)::float
+                                 / (total_connectors - error_count - unavailable_count)
+                   END)
+        FROM station_availability
+        WHERE station_id = $1 AND recorded_at >= $2 AND recorded_at < $3
+        GROUP BY bucket_start
+        ORDER BY bucket_start`
+
+    rows, err := s.db.QueryContext(ctx, query, stationID, from, to, bucket.Seconds())
+    if err != nil {
+        return nil, err
+    }
+    defer rows.Close()
+
+    buckets := []AvailabilityBucket{}
+    for rows.Next() {
+        var b AvailabilityBucket
+        var occupancy sql.NullFloat64
+        if err := rows.Scan(&b.Start, &b.Samples, &b.AvgACAvailable, &b.AvgDCAvailable,
+            &b.ACFreeRatio, &b.DCFreeRatio, &occupancy); err != nil {
+            return nil, err
+        }
+        if occupancy.Valid {
+            v := occupancy.Float64
+            b.Occupancy = &v
+        }
+        buckets = append(buckets, b)
+    }
+    return buckets, rows.Err()
+}
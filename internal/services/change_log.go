@@ -0,0 +1,130 @@
+package services
+
+import (
+    "context"
+    "database/sql"
+    "encoding/json"
+    "fmt"
+    "log"
+    "time"
+
+    "github.com/lib/pq"
+)
+
+// Yazılmayı bekleyen yenileme sayısı; kuyruk dolarsa yenileme bekler
+const changeLogQueueSize = 64
+
+// ChangeLog, istasyon değişikliklerini station_changes tablosunda yalnızca
+// ekleme yapılan bir günlük olarak saklar. Yazmalar tek bir işçi tarafından
+// yenileme sırasıyla yapılır; böylece id sırası yenileme sırasını izler ve
+// after_id ile sayfalayan istemciler satır kaçırmaz.
+type ChangeLog struct {
+    db    *sql.DB
+    queue chan []StationChange
+}
+
+func NewChangeLog(db *sql.DB) *ChangeLog {
+    return &ChangeLog{db: db, queue: make(chan []StationChange, changeLogQueueSize)}
+}
+
+// Start, kuyruktaki değişiklikleri sırayla yazan işçiyi başlatır
+func (l *ChangeLog) Start(ctx context.Context) {
+    go func() {
+        for {
+            select {
+            case <-ctx.Done():
+                return
+            case changes := <-l.queue:
+                l.write(changes)
+            }
+        }
+    }()
+}
+
+func (l *ChangeLog) Append(ctx context.Context, changes []StationChange) error {
+    if len(changes) == 0 {
+        return nil
+    }
+
+    tx, err := l.db.BeginTx(ctx, nil)
+    if err != nil {
+        return fmt.Errorf("transaction başlatılamadı: %v", err)
+    }
+    defer tx.Rollback()
+
+    stmt, err := tx.PrepareContext(ctx, `
+        INSERT INTO station_changes (station_id, change_type, details, detected_at)
+        VALUES ($1, $2, $3, $4)`)
+    if err != nil {
+        return err
+    }
+    defer stmt.Close()
+
+    for _, change := range changes {
+        details, err := json.Marshal(change)
+        if err != nil {
+            return err
+        }
+        if _, err := stmt.ExecContext(ctx, change.StationKey, change.Type, string(details), change.DetectedAt); err != nil {
+            return fmt.Errorf("değişiklik yazılamadı: %v", err)
+        }
+    }
+    return tx.Commit()
+}
+
+// RecordChanges, StationService.OnChanges'e verilecek dinleyicidir.
+// Değişiklikleri işçinin kuyruğuna ekler.
+func (l *ChangeLog) RecordChanges(changes []StationChange) {
+    l.queue <- changes
+}
+
+func (l *ChangeLog) write(changes []StationChange) {
+    ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+    defer cancel()
+
+    if err := l.Append(ctx, changes); err != nil {
+        log.Printf("İstasyon değişiklikleri kaydedilemedi: %v", err)
+        return
+    }
+    log.Printf("%d istasyon değişikliği kaydedildi", len(changes))
+}
+
+// Since, since anından (dahil) sonraki değişiklikleri id sırasıyla döndürür.
+// afterID, önceki sayfanın son id'si verilerek sonraki sayfayı almak için kullanılır.
+func (l *ChangeLog) Since(ctx context.Context, since time.Time, afterID int64, types []string, limit int) ([]StationChange, error) {
+    query := `
+        SELECT id, details
+        FROM station_changes
+        WHERE detected_at >= $1 AND id > $2
+          AND (cardinality($3::text[]) = 0 OR change_type = ANY($3::text[]))
+        ORDER BY id
+        LIMIT $4`
+
+    // nil dizi NULL olarak gider; filtre yoksa boş dizi gönder
+    if types == nil {
+        types = []string{}
+    }
+
+    rows, err := l.db.QueryContext(ctx, query, since, afterID, pq.Array(types), limit)
+    if err != nil {
+        return nil, err
+    }
+    defer rows.Close()
+
+    changes := []StationChange{}
+    for rows.Next() {
+        var id int64
+        var details []byte
+        if err := rows.Scan(&id, &details); err != nil {
+            return nil, err
+        }
+
+        var change StationChange
+        if err := json.Unmarshal(details, &change); err != nil {
+            return nil, fmt.Errorf("değişiklik %d okunamadı: %v", id, err)
+        }
+        change.ID = id
+        changes = append(changes, change)
+    }
+    return changes, rows.Err()
+}
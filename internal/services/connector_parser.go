@@ -0,0 +1,142 @@
+package services
+
+import (
+    "charging-stations-backend/internal/models"
+    "regexp"
+    "strconv"
+    "strings"
+)
+
+// Standart adlarının feed'lerde görülen yazımları. Sıra önemlidir:
+// "ccs1" ve "gb/t dc" gibi özel yazımlar genel olanlardan önce denenir.
+var connectorAliases = []struct {
+    pattern  *regexp.Regexp
+    standard string
+}{
+    {regexp.MustCompile(`ccs\s*-?\s*1|combo\s*-?\s*1|cst\s*-?\s*1`), models.ConnectorCCS1},
+    {regexp.MustCompile(`ccs|combo`), models.ConnectorCCS2},
+    {regexp.MustCompile(`chademo|cha\s*de\s*mo`), models.ConnectorCHAdeMO},
+    {regexp.MustCompile(`gb\s*/?\s*t\s*-?\s*dc`), models.ConnectorGBTDC},
+    {regexp.MustCompile(`gb\s*/?\s*t`), models.ConnectorGBTAC},
+    {regexp.MustCompile(`(type|tip|typ)\s*-?\s*1|j1772`), models.ConnectorType1},
+    {regexp.MustCompile(`(type|tip|typ)\s*-?\s*2|mennekes|\bt2\b`), models.ConnectorType2},
+    {regexp.MustCompile(`tesla|nacs|supercharger`), models.ConnectorTesla},
+    {regexp.MustCompile(`schuko|domestic`), models.ConnectorSchuko},
+}
+
+// Soket listesinde güç yazmıyorsa kullanılan tipik güçler
+const (
+    defaultACPowerKW = 22
+    defaultDCPowerKW = 50
+)
+
+var (
+    connectorSeparators = regexp.MustCompile(`[,;|+\n]`)
+    // "7,4 kW" gibi virgüllü ondalıkların ayırıcı sayılmaması için. Yalnızca
+    // ardından kW gelen sayılar dönüştürülür; "x2,2 adet" gibi girdiler ayrılır.
+    decimalComma     = regexp.MustCompile(`(?i)(\d),(\d+\s*kw)`)
+    connectorPowerKW = regexp.MustCompile(`(\d+(?:[.,]\d+)?)\s*kw`)
+    // "AC22", "DC 180" gibi birimsiz güç yazımı
+    connectorPowerPrefix = regexp.MustCompile(`\b(ac|dc)\s*-?\s*(\d+(?:[.,]\d+)?)\b`)
+    connectorCountPrefix = regexp.MustCompile(`^(\d+)\s*(?:x|×|adet)\s*`)
+    connectorCountSuffix = regexp.MustCompile(`(?:x|×)\s*(\d+)$|\((\d+)\)$|(\d+)\s*adet$`)
+    connectorDC          = regexp.MustCompile(`\bdc\b|\bdc\d`)
+    connectorAC          = regexp.MustCompile(`\bac\b|\bac\d`)
+)
+
+// ParseConnectorList, feed'lerin serbest metin soket listesini (ör.
+// "CCS2 180kW x2, Type 2 22kW") tipli soketlere çevirir. Aynı standart, tip ve
+// güçteki girdiler tek kayıtta toplanır; tanınmayan parçalar Unknown olarak döner.
+func ParseConnectorList(list string) []models.Connector {
+    var connectors []models.Connector
+    index := make(map[models.Connector]int)
+
+    list = decimalComma.ReplaceAllString(list, "$1.$2")
+    for _, part := range connectorSeparators.Split(list, -1) {
+        connector, ok := parseConnector(part)
+        if !ok {
+            continue
+        }
+
+        key := connector
+        key.Count = 0
+        if i, exists := index[key]; exists {
+            connectors[i].Count += connector.Count
+            continue
+        }
+        index[key] = len(connectors)
+        connectors = append(connectors, connector)
+    }
+    return connectors
+}
+
+func parseConnector(part string) (models.Connector, bool) {
+    text := strings.ToLower(strings.TrimSpace(part))
+    if text == "" {
+        return models.Connector{}, false
+    }
+
+    connector := models.Connector{
+        Standard: models.ConnectorUnknown,
+        Count:    1,
+    }
+
+    if m := connectorCountPrefix.FindStringSubmatch(text); m != nil {
+        connector.Count, _ = strconv.Atoi(m[1])
+        text = strings.TrimSpace(text[len(m[0]):])
+    } else if m := connectorCountSuffix.FindStringSubmatch(text); m != nil {
+        for _, g := range m[1:] {
+            if g != "" {
+                connector.Count, _ = strconv.Atoi(g)
+            }
+        }
+        text = strings.TrimSpace(text[:len(text)-len(m[0])])
+    }
+    if connector.Count <= 0 {
+        connector.Count = 1
+    }
+
+    if m := connectorPowerKW.FindStringSubmatch(text); m != nil {
+        connector.MaxPowerKW = parseDecimal(m[1])
+        text = strings.Replace(text, m[0], " ", 1)
+    } else if m := connectorPowerPrefix.FindStringSubmatch(text); m != nil {
+        connector.MaxPowerKW = parseDecimal(m[2])
+    }
+
+    for _, alias := range connectorAliases {
+        if alias.pattern.MatchString(text) {
+            connector.Standard = alias.standard
+            break
+        }
+    }
+
+    switch {
+    case connectorDC.MatchString(text):
+        connector.PowerType = models.PowerTypeDC
+    case connectorAC.MatchString(text):
+        connector.PowerType = models.PowerTypeAC
+    default:
+        connector.PowerType = defaultPowerType(connector.Standard, connector.MaxPowerKW)
+    }
+
+    return connector, true
+}
+
+// defaultPowerType, metinde AC/DC geçmiyorsa standarttan tipi çıkarır
+func defaultPowerType(standard string, powerKW float64) string {
+    switch standard {
+    case models.ConnectorCCS1, models.ConnectorCCS2, models.ConnectorCHAdeMO, models.ConnectorGBTDC, models.ConnectorTesla:
+        return models.PowerTypeDC
+    case models.ConnectorUnknown:
+        // 43 kW üstü AC soket pratikte yok
+        if powerKW > 43 {
+            return models.PowerTypeDC
+        }
+    }
+    return models.PowerTypeAC
+}
+
+func parseDecimal(s string) float64 {
+    v, _ := strconv.ParseFloat(strings.Replace(s, ",", ".", 1), 64)
+    return v
+}
@@ -0,0 +1,85 @@
+package services
+
+import (
+    "charging-stations-backend/internal/models"
+    "reflect"
+    "regexp"
+    "testing"
+)
+
+func TestParseConnectorList(t *testing.T) {
+    ac := func(standard string, kw float64, count int) models.Connector {
+        return models.Connector{Standard: standard, PowerType: models.PowerTypeAC, MaxPowerKW: kw, Count: count}
+    }
+    dc := func(standard string, kw float64, count int) models.Connector {
+        return models.Connector{Standard: standard, PowerType: models.PowerTypeDC, MaxPowerKW: kw, Count: count}
+    }
+
+    tests := []struct {
+        name string
+        list string
+        want []models.Connector
+    }{
+        {"empty", "", nil},
+        {"ccs2 and type2 with counts", "CCS2 180kW x2, Type 2 22kW", []models.Connector{
+            dc(models.ConnectorCCS2, 180, 2),
+            ac(models.ConnectorType2, 22, 1),
+        }},
+        {"unitless power prefix", "DC180 CCS, AC22 Tip-2", []models.Connector{
+            dc(models.ConnectorCCS2, 180, 1),
+            ac(models.ConnectorType2, 22, 1),
+        }},
+        {"turkish count and decimal comma", "2 adet Type2 7,4 kW; CHAdeMO 50 kW", []models.Connector{
+            ac(models.ConnectorType2, 7.4, 2),
+            dc(models.ConnectorCHAdeMO, 50, 1),
+        }},
+        {"count suffix in parentheses", "CCS 120kW (2) | CCS 120kW", []models.Connector{
+            dc(models.ConnectorCCS2, 120, 3),
+        }},
+        {"t2 alias", "T2 22kW x4", []models.Connector{
+            ac(models.ConnectorType2, 22, 4),
+        }},
+        {"comma separator before a count", "CCS 50kW x2,2 adet Type2 22kW", []models.Connector{
+            dc(models.ConnectorCCS2, 50, 2),
+            ac(models.ConnectorType2, 22, 2),
+        }},
+        {"comma separator between unitless powers", "AC22,2xDC50", []models.Connector{
+            ac(models.ConnectorUnknown, 22, 1),
+            dc(models.ConnectorUnknown, 50, 2),
+        }},
+        {"comma separator before a prefixed count", "Type2 22kW x1,1 x CCS 50kW", []models.Connector{
+            ac(models.ConnectorType2, 22, 1),
+            dc(models.ConnectorCCS2, 50, 1),
+        }},
+        {"gbt variants", "GB/T DC 60kW, GBT 7kW", []models.Connector{
+            dc(models.ConnectorGBTDC, 60, 1),
+            ac(models.ConnectorGBTAC, 7, 1),
+        }},
+        {"ccs1 before ccs", "Combo 1 50kW", []models.Connector{
+            dc(models.ConnectorCCS1, 50, 1),
+        }},
+    }
+
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            got := ParseConnectorList(tt.list)
+            if !reflect.DeepEqual(got, tt.want) {
+                t.Errorf("ParseConnectorList(%q)\n got %+v\nwant %+v", tt.list, got, tt.want)
+            }
+        })
+    }
+}
+
+func TestType2AliasIsAnchored(t *testing.T) {
+    var type2 *regexp.Regexp
+    for _, alias := range connectorAliases {
+        if alias.standard == models.ConnectorType2 {
+            type2 = alias.pattern
+        }
+    }
+    for text, want := range map[string]bool{"t2": true, "t2 22kw": true, "soket2": false, "gt2x": false} {
+        if got := type2.MatchString(text); got != want {
+            t.Errorf("type2 alias matches %q = %v, want %v", text, got, want)
+        }
+    }
+}
@@ -0,0 +1,131 @@
+package services
+
+import (
+    "context"
+    "math"
+    "sort"
+    "sync"
+    "time"
+
+    "googlemaps.github.io/maps"
+)
+
+const (
+    // Sürüş süreleri trafikle değiştiği için kısa süre saklanır
+    drivingETATTL = 10 * time.Minute
+    // Başlangıç noktası ~100 m'lik hücrelere yuvarlanır; yakın kullanıcılar önbelleği paylaşır
+    drivingETAPrecision  = 1000
+    maxDrivingETAEntries = 20000
+)
+
+type drivingETAKey struct {
+    lat, lng   int64
+    stationKey string
+}
+
+type drivingETA struct {
+    cell       MatrixCell
+    computedAt time.Time
+}
+
+// DrivingRanker, kuş uçuşu ile seçilmiş aday istasyonları sürüş mesafesi ve
+// tahmini varış süresine göre yeniden sıralar. Yol mesafeleri önbellekte
+// tutulur; kuş uçuşu tahminler saklanmaz, bir sonraki istekte yeniden denenir.
+type DrivingRanker struct {
+    mapService *MapService
+
+    mu      sync.Mutex
+    entries map[drivingETAKey]drivingETA
+}
+
+func NewDrivingRanker(ms *MapService) *DrivingRanker {
+    return &DrivingRanker{
+        mapService: ms,
+        entries:    make(map[drivingETAKey]drivingETA),
+    }
+}
+
+// Rank, istasyonlara sürüş mesafesi/süresini yazar ve süreye göre sıralar.
+// Yol mesafesi alınamayan istasyonlar listenin sonuna eklenir.
+// Kuş uçuşu mesafeler (DistanceKM) olduğu gibi korunur.
+func (r *DrivingRanker) Rank(ctx context.Context, lat, lon float64, stations []Station) []Station {
+    now := time.Now()
+    origin := maps.LatLng{Lat: lat, Lng: lon}
+
+    var missing []int
+    r.mu.Lock()
+    for i := range stations {
+        entry, ok := r.entries[r.key(lat, lon, stations[i].Key)]
+        if ok && now.Sub(entry.computedAt) < drivingETATTL {
+            applyDrivingETA(&stations[i], entry)
+        } else {
+            missing = append(missing, i)
+        }
+    }
+    r.mu.Unlock()
+
+    if len(missing) > 0 {
+        destinations := make([]maps.LatLng, len(missing))
+        for j, i := range missing {
+            destinations[j] = maps.LatLng{Lat: stations[i].Latitude, Lng: stations[i].Longitude}
+        }
+        row := r.mapService.GetDistanceMatrix(ctx, []maps.LatLng{origin}, destinations)[0]
+
+        r.mu.Lock()
+        r.pruneLocked(now)
+        for j, i := range missing {
+            entry := drivingETA{cell: row[j], computedAt: now}
+            if entry.cell.Kind == MatrixCellRoad {
+                r.entries[r.key(lat, lon, stations[i].Key)] = entry
+            }
+            applyDrivingETA(&stations[i], entry)
+        }
+        r.mu.Unlock()
+    }
+
+    // Yol mesafesi bilinenler önce gelir; kuş uçuşu süreler gerçek sürüş
+    // süresinden kısa olduğu için onlarla aynı ölçekte karşılaştırılmaz
+    sort.SliceStable(stations, func(i, j int) bool {
+        a, b := stations[i], stations[j]
+        if aRoad, bRoad := a.DrivingDistanceKind == MatrixCellRoad, b.DrivingDistanceKind == MatrixCellRoad; aRoad != bRoad {
+            return aRoad
+        }
+        if a.DrivingDurationSeconds == b.DrivingDurationSeconds {
+            return a.DrivingDistanceKM < b.DrivingDistanceKM
+        }
+        return a.DrivingDurationSeconds < b.DrivingDurationSeconds
+    })
+    return stations
+}
+
+func (r *DrivingRanker) key(lat, lon float64, stationKey string) drivingETAKey {
+    return drivingETAKey{
+        lat:        int64(math.Round(lat * drivingETAPrecision)),
+        lng:        int64(math.Round(lon * drivingETAPrecision)),
+        stationKey: stationKey,
+    }
+}
+
+// pruneLocked, önbellek sınırı aşıldığında süresi dolan kayıtları siler;
+// yine de sığmıyorsa önbelleği boşaltır
+func (r *DrivingRanker) pruneLocked(now time.Time) {
+    if len(r.entries) < maxDrivingETAEntries {
+        return
+    }
+    for key, entry := range r.entries {
+        if now.Sub(entry.computedAt) >= drivingETATTL {
+            delete(r.entries, key)
+        }
+    }
+    if len(r.entries) >= maxDrivingETAEntries {
+        r.entries = make(map[drivingETAKey]drivingETA)
+    }
+}
+
+func applyDrivingETA(station *Station, entry drivingETA) {
+    computedAt := entry.computedAt
+    station.DrivingDistanceKM = entry.cell.DistanceKM
+    station.DrivingDurationSeconds = entry.cell.DurationSeconds
+    station.DrivingDistanceKind = entry.cell.Kind
+    station.ETAUpdatedAt = &computedAt
+}
@@ -0,0 +1,226 @@
+package services
+
+import (
+    "context"
+    "fmt"
+    "strings"
+
+    "googlemaps.github.io/maps"
+)
+
+// Distance Matrix istek sınırları
+const (
+    googleMatrixMaxDestinations = 25
+    googleMatrixMaxElements     = 100
+)
+
+// GoogleMapsClient, kullanılan Google Maps API çağrılarıdır. *maps.Client bu
+// arayüzü sağlar; testlerde sahte bir istemci verilebilir.
+type GoogleMapsClient interface {
+    Directions(ctx context.Context, r *maps.DirectionsRequest) ([]maps.Route, []maps.GeocodedWaypoint, error)
+    DistanceMatrix(ctx context.Context, r *maps.DistanceMatrixRequest) (*maps.DistanceMatrixResponse, error)
+}
+
+// GoogleRoutingProvider, Google Directions ve Distance Matrix API'lerini kullanır.
+// Ücretli çağrıların sonuçları cache verilirse önbelleğe alınır.
+type GoogleRoutingProvider struct {
+    client GoogleMapsClient
+    cache  *RouteCache
+}
+
+func NewGoogleRoutingProvider(apiKey string, cache *RouteCache) (*GoogleRoutingProvider, error) {
+    client, err := maps.NewClient(maps.WithAPIKey(apiKey))
+    if err != nil {
+        return nil, err
+    }
+    return NewGoogleRoutingProviderWithClient(client, cache), nil
+}
+
+// NewGoogleRoutingProviderWithClient, hazır bir istemciyle (ör. sahte istemci) sağlayıcı oluşturur
+func NewGoogleRoutingProviderWithClient(client GoogleMapsClient, cache *RouteCache) *GoogleRoutingProvider {
+    return &GoogleRoutingProvider{client: client, cache: cache}
+}
+
+func (p *GoogleRoutingProvider) Name() string {
+    return "google"
+}
+
+// Route, Directions API'sinden rotayı alır. Kalkış saati verilirse trafikli
+// süre de döner; alternatives ile Google'ın önerdiği diğer rotalar eklenir.
+func (p *GoogleRoutingProvider) Route(ctx context.Context, origin, destination maps.LatLng, opts TravelOptions) (*RouteResult, error) {
+    key := routeCacheKey(routeCacheDirections, string(maps.TravelModeDriving), origin, destination, opts.cacheKeyParts()...)
+    var cached RouteResult
+    if p.cache.Get(ctx, key, &cached) {
+        return &cached, nil
+    }
+
+    r := &maps.DirectionsRequest{
+        Origin:        origin.String(),
+        Destination:   destination.String(),
+        Mode:          maps.TravelModeDriving,
+        DepartureTime: opts.googleDepartureTime(),
+        ArrivalTime:   opts.googleArrivalTime(),
+        TrafficModel:  maps.TrafficModel(opts.TrafficModel),
+        Alternatives:  opts.Alternatives,
+    }
+
+    resp, _, err := p.client.Directions(ctx, r)
+    if err != nil {
+        return nil, &RoutingError{Status: googleStatus(err), Err: err}
+    }
+
+    if len(resp) == 0 {
+        return nil, &RoutingError{Status: "ZERO_RESULTS", Err: fmt.Errorf("no route found")}
+    }
+
+    result, err := newRouteResult(resp[0])
+    if err != nil {
+        return nil, err
+    }
+    for _, route := range resp[1:] {
+        alt, err := newRouteResult(route)
+        if err != nil {
+            return nil, err
+        }
+        result.Alternatives = append(result.Alternatives, alt)
+    }
+    p.cache.Set(ctx, key, result)
+    return result, nil
+}
+
+func (p *GoogleRoutingProvider) Distance(ctx context.Context, origin, destination maps.LatLng, opts TravelOptions) (*DistanceResult, error) {
+    opts.Alternatives = false
+    key := routeCacheKey(routeCacheDistance, string(maps.TravelModeDriving), origin, destination, opts.cacheKeyParts()...)
+    var cached DistanceResult
+    if p.cache.Get(ctx, key, &cached) {
+        cached.Source = DistanceSourceCache
+        return &cached, nil
+    }
+
+    r := &maps.DistanceMatrixRequest{
+        Origins:       []string{origin.String()},
+        Destinations:  []string{destination.String()},
+        Mode:          maps.TravelModeDriving,
+        DepartureTime: opts.googleDepartureTime(),
+        ArrivalTime:   opts.googleArrivalTime(),
+        TrafficModel:  maps.TrafficModel(opts.TrafficModel),
+    }
+
+    resp, err := p.client.DistanceMatrix(ctx, r)
+    if err != nil {
+        return nil, &RoutingError{Status: googleStatus(err), Err: err}
+    }
+    if len(resp.Rows) == 0 || len(resp.Rows[0].Elements) == 0 {
+        return nil, &RoutingError{Status: RoutingStatusRequestFailed, Err: fmt.Errorf("empty distance matrix response")}
+    }
+
+    element := resp.Rows[0].Elements[0]
+    if element.Status != "OK" {
+        return nil, &RoutingError{Status: element.Status, Err: fmt.Errorf("distance matrix element status %s", element.Status)}
+    }
+    result := &DistanceResult{
+        Distance:          float64(element.Distance.Meters) / 1000,
+        Duration:          int64(element.Duration.Seconds()),
+        DurationInTraffic: int64(element.DurationInTraffic.Seconds()),
+        Source:            p.Name(),
+        UpstreamStatus:    element.Status,
+    }
+    p.cache.Set(ctx, key, result)
+    return result, nil
+}
+
+// Matrix, önbellekte olmayan hücreler için Distance Matrix API'sini istek
+// başına eleman sınırına uyan bloklarla çağırır. Bir blok başarısız olursa
+// diğerleri yine de denenir.
+func (p *GoogleRoutingProvider) Matrix(ctx context.Context, origins, destinations []maps.LatLng) ([][]*DistanceResult, error) {
+    grid := newDistanceGrid(len(origins), len(destinations))
+    filled := false
+
+    // Önbellekten doldur, eksik hücrelerin satır ve sütunlarını topla
+    var missingOrigins, missingDestinations []int
+    missingDest := make(map[int]bool)
+    for i, origin := range origins {
+        rowMissing := false
+        for j, destination := range destinations {
+            var cached DistanceResult
+            if p.cache.Get(ctx, routeCacheKey(routeCacheDistance, string(maps.TravelModeDriving), origin, destination), &cached) {
+                cached.Source = DistanceSourceCache
+                grid[i][j] = &cached
+                filled = true
+                continue
+            }
+            rowMissing = true
+            if !missingDest[j] {
+                missingDest[j] = true
+                missingDestinations = append(missingDestinations, j)
+            }
+        }
+        if rowMissing {
+            missingOrigins = append(missingOrigins, i)
+        }
+    }
+
+    var lastErr error
+    for d := 0; d < len(missingDestinations); d += googleMatrixMaxDestinations {
+        dIdx := missingDestinations[d:minInt(d+googleMatrixMaxDestinations, len(missingDestinations))]
+        rowsPerRequest := googleMatrixMaxElements / len(dIdx)
+
+        for o := 0; o < len(missingOrigins); o += rowsPerRequest {
+            oIdx := missingOrigins[o:minInt(o+rowsPerRequest, len(missingOrigins))]
+            resp, err := p.client.DistanceMatrix(ctx, &maps.DistanceMatrixRequest{
+                Origins:      latLngStrings(origins, oIdx),
+                Destinations: latLngStrings(destinations, dIdx),
+                Mode:         maps.TravelModeDriving,
+            })
+            if err != nil {
+                lastErr = err
+                continue
+            }
+
+            for i, row := range resp.Rows {
+                for j, element := range row.Elements {
+                    if element.Status != "OK" || i >= len(oIdx) || j >= len(dIdx) {
+                        continue
+                    }
+                    oi, dj := oIdx[i], dIdx[j]
+                    result := &DistanceResult{
+                        Distance:       float64(element.Distance.Meters) / 1000,
+                        Duration:       int64(element.Duration.Seconds()),
+                        Source:         p.Name(),
+                        UpstreamStatus: element.Status,
+                    }
+                    grid[oi][dj] = result
+                    p.cache.Set(ctx, routeCacheKey(routeCacheDistance, string(maps.TravelModeDriving), origins[oi], destinations[dj]), result)
+                    filled = true
+                }
+            }
+        }
+    }
+
+    if !filled && lastErr != nil {
+        return nil, lastErr
+    }
+    return grid, nil
+}
+
+// latLngStrings, verilen indekslerdeki noktaları API'nin beklediği metne çevirir
+func latLngStrings(points []maps.LatLng, indexes []int) []string {
+    result := make([]string, len(indexes))
+    for i, idx := range indexes {
+        result[i] = points[idx].String()
+    }
+    return result
+}
+
+// googleStatus, istemci hatasından API durum kodunu çıkarır. İstemci OK dışı
+// durumları "maps: STATUS - mesaj" biçiminde döndürür.
+func googleStatus(err error) string {
+    msg := strings.TrimPrefix(err.Error(), "maps: ")
+    if msg == err.Error() {
+        return RoutingStatusRequestFailed
+    }
+    if i := strings.Index(msg, " - "); i > 0 {
+        return msg[:i]
+    }
+    return RoutingStatusRequestFailed
+}
@@ -0,0 +1,206 @@
+package services
+
+import (
+    "context"
+    "errors"
+    "sync"
+    "testing"
+    "time"
+
+    "googlemaps.github.io/maps"
+)
+
+// fakeGoogleClient, çağrıları sayan ve sabit yanıtlar dönen sahte bir GoogleMapsClient'tır
+type fakeGoogleClient struct {
+    mu             sync.Mutex
+    directions     int
+    distanceMatrix int
+    err            error
+}
+
+func (c *fakeGoogleClient) Directions(ctx context.Context, r *maps.DirectionsRequest) ([]maps.Route, []maps.GeocodedWaypoint, error) {
+    c.mu.Lock()
+    defer c.mu.Unlock()
+    c.directions++
+    if c.err != nil {
+        return nil, nil, c.err
+    }
+    start, end := maps.LatLng{Lat: 41.0082, Lng: 28.9784}, maps.LatLng{Lat: 39.9334, Lng: 32.8597}
+    return []maps.Route{{
+        Summary:          "O-4",
+        OverviewPolyline: maps.Polyline{Points: maps.Encode([]maps.LatLng{start, end})},
+        Legs: []*maps.Leg{{
+            StartLocation: start,
+            EndLocation:   end,
+            Distance:      maps.Distance{Meters: 450000},
+            Duration:      5 * time.Hour,
+        }},
+    }}, nil, nil
+}
+
+func (c *fakeGoogleClient) DistanceMatrix(ctx context.Context, r *maps.DistanceMatrixRequest) (*maps.DistanceMatrixResponse, error) {
+    c.mu.Lock()
+    defer c.mu.Unlock()
+    c.distanceMatrix++
+    if c.err != nil {
+        return nil, c.err
+    }
+    resp := &maps.DistanceMatrixResponse{}
+    for range r.Origins {
+        row := maps.DistanceMatrixElementsRow{}
+        for range r.Destinations {
+            row.Elements = append(row.Elements, &maps.DistanceMatrixElement{
+                Status:   "OK",
+                Distance: maps.Distance{Meters: 12345},
+                Duration: 15 * time.Minute,
+            })
+        }
+        resp.Rows = append(resp.Rows, row)
+    }
+    return resp, nil
+}
+
+func (c *fakeGoogleClient) calls() (int, int) {
+    c.mu.Lock()
+    defer c.mu.Unlock()
+    return c.directions, c.distanceMatrix
+}
+
+// expireRouteCache, bellekteki tüm kayıtların süresini doldurur
+func expireRouteCache(c *RouteCache) {
+    c.mu.Lock()
+    defer c.mu.Unlock()
+    for _, el := range c.entries {
+        el.Value.(*routeCacheEntry).expiresAt = time.Now().Add(-time.Second)
+    }
+}
+
+var (
+    testOrigin      = maps.LatLng{Lat: 41.0082, Lng: 28.9784}
+    testDestination = maps.LatLng{Lat: 39.9334, Lng: 32.8597}
+)
+
+func TestGoogleDistanceCacheHit(t *testing.T) {
+    client := &fakeGoogleClient{}
+    cache := NewRouteCache(nil, time.Hour, 10)
+    p := NewGoogleRoutingProviderWithClient(client, cache)
+    ctx := context.Background()
+
+    first, err := p.Distance(ctx, testOrigin, testDestination, TravelOptions{})
+    if err != nil {
+        t.Fatal(err)
+    }
+    if first.Source != "google" || first.Distance != 12.345 {
+        t.Fatalf("first result = %+v", first)
+    }
+
+    // ~11 m içindeki nokta aynı kaydı kullanır
+    nearby := maps.LatLng{Lat: testOrigin.Lat + 0.00001, Lng: testOrigin.Lng}
+    second, err := p.Distance(ctx, nearby, testDestination, TravelOptions{})
+    if err != nil {
+        t.Fatal(err)
+    }
+    if second.Source != DistanceSourceCache || second.Distance != first.Distance || second.Duration != first.Duration {
+        t.Fatalf("second result = %+v, want cached copy of %+v", second, first)
+    }
+    if _, calls := client.calls(); calls != 1 {
+        t.Errorf("DistanceMatrix called %d times, want 1", calls)
+    }
+
+    stats := cache.Stats()
+    if stats.Hits != 1 || stats.Misses != 1 || stats.Entries != 1 || stats.Evictions != 0 {
+        t.Errorf("stats = %+v", stats)
+    }
+}
+
+func TestGoogleRouteCacheTTLExpiry(t *testing.T) {
+    client := &fakeGoogleClient{}
+    cache := NewRouteCache(nil, time.Hour, 10)
+    p := NewGoogleRoutingProviderWithClient(client, cache)
+    ctx := context.Background()
+
+    for i := 0; i < 2; i++ {
+        if _, err := p.Route(ctx, testOrigin, testDestination, TravelOptions{}); err != nil {
+            t.Fatal(err)
+        }
+    }
+    if calls, _ := client.calls(); calls != 1 {
+        t.Fatalf("Directions called %d times before expiry, want 1", calls)
+    }
+
+    expireRouteCache(cache)
+    route, err := p.Route(ctx, testOrigin, testDestination, TravelOptions{})
+    if err != nil {
+        t.Fatal(err)
+    }
+    if route.DistanceMeters != 450000 {
+        t.Errorf("distance = %d m", route.DistanceMeters)
+    }
+    if calls, _ := client.calls(); calls != 2 {
+        t.Errorf("Directions called %d times after expiry, want 2", calls)
+    }
+
+    stats := cache.Stats()
+    if stats.Hits != 1 || stats.Misses != 2 || stats.Entries != 1 {
+        t.Errorf("stats = %+v", stats)
+    }
+}
+
+func TestRouteCacheLRUEviction(t *testing.T) {
+    client := &fakeGoogleClient{}
+    cache := NewRouteCache(nil, time.Hour, 2)
+    p := NewGoogleRoutingProviderWithClient(client, cache)
+    ctx := context.Background()
+
+    destinations := []maps.LatLng{
+        {Lat: 39.9334, Lng: 32.8597},
+        {Lat: 38.4237, Lng: 27.1428},
+        {Lat: 36.8969, Lng: 30.7133},
+    }
+    distance := func(i int) {
+        if _, err := p.Distance(ctx, testOrigin, destinations[i], TravelOptions{}); err != nil {
+            t.Fatal(err)
+        }
+    }
+
+    distance(0)
+    distance(1)
+    distance(0) // 0 en son kullanılan olur
+    distance(2) // 1 çıkarılır
+    if _, calls := client.calls(); calls != 3 {
+        t.Fatalf("DistanceMatrix called %d times, want 3", calls)
+    }
+
+    distance(0)
+    if _, calls := client.calls(); calls != 3 {
+        t.Errorf("recently used entry was evicted")
+    }
+    distance(1)
+    if _, calls := client.calls(); calls != 4 {
+        t.Errorf("least recently used entry was not evicted")
+    }
+
+    stats := cache.Stats()
+    want := RouteCacheStats{Entries: 2, MaxEntries: 2, TTLSeconds: 3600, Hits: 2, Misses: 4, Evictions: 2}
+    if stats != want {
+        t.Errorf("stats = %+v, want %+v", stats, want)
+    }
+}
+
+func TestGoogleRouteWrapsUpstreamStatus(t *testing.T) {
+    client := &fakeGoogleClient{err: errors.New("maps: OVER_QUERY_LIMIT - You have exceeded your daily request quota")}
+    cache := NewRouteCache(nil, time.Hour, 10)
+    p := NewGoogleRoutingProviderWithClient(client, cache)
+
+    _, err := p.Route(context.Background(), testOrigin, testDestination, TravelOptions{})
+    var routingErr *RoutingError
+    if !errors.As(err, &routingErr) || routingErr.Status != "OVER_QUERY_LIMIT" {
+        t.Fatalf("err = %v, want RoutingError with OVER_QUERY_LIMIT", err)
+    }
+    if attempt := newRoutingAttempt(p.Name(), err); attempt.Status != "OVER_QUERY_LIMIT" {
+        t.Errorf("attempt status = %q", attempt.Status)
+    }
+    if stats := cache.Stats(); stats.Entries != 0 {
+        t.Errorf("failed route was cached: %+v", stats)
+    }
+}
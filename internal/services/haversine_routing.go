@@ -0,0 +1,63 @@
+package services
+
+import (
+    "context"
+    "math"
+
+    "charging-stations-backend/internal/utils"
+    "googlemaps.github.io/maps"
+)
+
+// Kuş uçuşu tahminde varsayılan ortalama hız (km/s)
+const haversineAverageSpeedKMH = 60
+
+// HaversineRoutingProvider, ağ erişimi olmadan kuş uçuşu mesafe ve
+// sabit hızla süre tahmini yapar. Zincirin son halkası olarak kullanılır.
+type HaversineRoutingProvider struct{}
+
+func NewHaversineRoutingProvider() *HaversineRoutingProvider {
+    return &HaversineRoutingProvider{}
+}
+
+func (p *HaversineRoutingProvider) Name() string {
+    return "haversine"
+}
+
+func (p *HaversineRoutingProvider) Distance(ctx context.Context, origin, destination maps.LatLng, opts TravelOptions) (*DistanceResult, error) {
+    distance := utils.CalculateDistance(origin.Lat, origin.Lng, destination.Lat, destination.Lng)
+    return &DistanceResult{
+        Distance: distance,
+        Duration: int64(distance / haversineAverageSpeedKMH * 3600),
+        Source:   DistanceSourceEstimate,
+    }, nil
+}
+
+// Route, iki noktayı düz bir çizgiyle birleştiren tek adımlı bir rota
+// döndürür. Zaman ve trafik seçenekleri yok sayılır.
+func (p *HaversineRoutingProvider) Route(ctx context.Context, origin, destination maps.LatLng, opts TravelOptions) (*RouteResult, error) {
+    estimate, _ := p.Distance(ctx, origin, destination, opts)
+    meters := int(math.Round(estimate.Distance * 1000))
+    line := []maps.LatLng{origin, destination}
+
+    return &RouteResult{
+        DistanceMeters:  meters,
+        DurationSeconds: estimate.Duration,
+        Bounds:          boundsOf(line),
+        Polyline:        RouteLine{Points: line},
+        Legs: []RouteLeg{{
+            Start:           origin,
+            End:             destination,
+            DistanceMeters:  meters,
+            DurationSeconds: estimate.Duration,
+            Steps: []RouteStep{{
+                Instruction:     "Head straight to destination",
+                DistanceMeters:  meters,
+                DurationSeconds: estimate.Duration,
+                Start:           origin,
+                End:             destination,
+                Polyline:        RouteLine{Points: line},
+            }},
+        }},
+        Warnings: []string{"Straight-line estimate, road network not considered"},
+    }, nil
+}
@@ -1,101 +1,241 @@
 package services
 
 import (
-    "googlemaps.github.io/maps"
-    "context"
     "charging-stations-backend/internal/utils"
-    "os"
+    "context"
     "fmt"
     "log"
+
+    "googlemaps.github.io/maps"
 )
 
+// MapService, rota ve mesafe isteklerini yönlendirme sağlayıcı zincirine
+// iletir; bir sağlayıcı hata verirse sıradakine geçer
 type MapService struct {
-    client *maps.Client
+    providers []RoutingProvider
 }
 
+// Rota planlamada kullanılan çözümlenmiş rota çizgisi
+type RoutePath struct {
+    Points          []maps.LatLng
+    DistanceKM      float64
+    DurationSeconds int64
+    Provider        string
+}
+
+// Mesafe sonucunun kaynağı. Canlı yol mesafelerinde kaynak sağlayıcının
+// adıdır (google, osrm, valhalla).
+const (
+    DistanceSourceCache    = "cache"
+    DistanceSourceEstimate = "estimate"
+)
+
+// Kaynağa göre güven değerleri. Önbellekteki sonuç trafik değişimlerini
+// kaçırabilir; kuş uçuşu tahmin yol mesafesini ve süresini olduğundan az gösterir.
+const (
+    confidenceLive     = 0.95
+    confidenceCache    = 0.9
+    confidenceEstimate = 0.3
+)
+
 type DistanceResult struct {
-    Distance float64 `json:"distance"` // metre cinsinden
+    Distance float64 `json:"distance"` // km cinsinden
     Duration int64   `json:"duration"` // saniye cinsinden
+    // Trafikli süre (saniye); yalnızca kalkış saatiyle istenen Google sonuçlarında doludur
+    DurationInTraffic int64   `json:"duration_in_traffic,omitempty"`
+    Provider          string  `json:"provider"`
+    Source            string  `json:"source"`
+    UpstreamStatus    string  `json:"upstream_status,omitempty"`
+    Confidence        float64 `json:"confidence"`
+    // Warnings, sonucu kısıtlayan durumlardır (ör. trafik desteklenmiyor)
+    Warnings []string `json:"warnings,omitempty"`
+    // Fallbacks, sonuca ulaşmadan önce başarısız olan sağlayıcılardır
+    Fallbacks []RoutingAttempt `json:"fallbacks,omitempty"`
 }
 
-func NewMapService() *MapService {
-    apiKey := os.Getenv("GOOGLE_MAPS_API_KEY")
-    if apiKey == "" {
-        log.Fatal("Google Maps API key is missing")
+// NewMapService, sağlayıcıları verilen sırayla dener. Hiç sağlayıcı
+// verilmezse kuş uçuşu tahmin kullanılır; sunucu harita anahtarı olmadan da açılır.
+func NewMapService(providers ...RoutingProvider) *MapService {
+    if len(providers) == 0 {
+        log.Printf("Yönlendirme sağlayıcısı yok, kuş uçuşu tahmin kullanılacak")
+        providers = []RoutingProvider{NewHaversineRoutingProvider()}
     }
 
-    client, err := maps.NewClient(maps.WithAPIKey(apiKey))
-    if err != nil {
-        log.Fatalf("Error creating Google Maps client: %v", err)
+    names := make([]string, len(providers))
+    for i, p := range providers {
+        names[i] = p.Name()
     }
-    return &MapService{client: client}
+    log.Printf("Yönlendirme sağlayıcıları: %v", names)
+
+    return &MapService{providers: providers}
 }
 
 func (s *MapService) CalculateDistance(lat1, lon1, lat2, lon2 float64) float64 {
     return utils.CalculateDistance(lat1, lon1, lat2, lon2)
 }
 
-func (s *MapService) GetDistance(origin, destination maps.LatLng) (*DistanceResult, error) {
-    // API çağrısı başarısız olursa kuş bakışı mesafeyi hesapla
-    distance := utils.CalculateDistance(
-        origin.Lat, 
-        origin.Lng, 
-        destination.Lat, 
-        destination.Lng,
-    )
-
-    // Ortalama hız 60 km/s varsayarak süreyi hesapla
-    duration := int64((distance / 60) * 3600) // saniye cinsinden
-
-    result := &DistanceResult{
-        Distance: distance,
-        Duration: duration,
-    }
-    
-    // Google Maps API'yi dene
-    if s.client != nil {
-        r := &maps.DistanceMatrixRequest{
-            Origins:      []string{fmt.Sprintf("%f,%f", origin.Lat, origin.Lng)},
-            Destinations: []string{fmt.Sprintf("%f,%f", destination.Lat, destination.Lng)},
-            Mode:         maps.TravelModeDriving,
+// GetDistance, zincirde ilk yanıt veren sağlayıcının sürüş mesafesini
+// kaynağı, yukarı akış durumu ve güven değeriyle döndürür. Hiçbir sağlayıcı
+// yol mesafesi veremezse kuş uçuşu tahmin döner; strict ise tahmin yerine
+// denemeleri içeren bir *NoRoadDistanceError döner.
+func (s *MapService) GetDistance(ctx context.Context, origin, destination maps.LatLng, opts TravelOptions, strict bool) (*DistanceResult, error) {
+    var attempts []RoutingAttempt
+    for _, p := range s.providers {
+        result, err := p.Distance(ctx, origin, destination, opts)
+        if err != nil {
+            log.Printf("%s mesafe hesaplayamadı, sıradaki sağlayıcı deneniyor: %v", p.Name(), err)
+            attempts = append(attempts, newRoutingAttempt(p.Name(), err))
+            continue
         }
-
-        resp, err := s.client.DistanceMatrix(context.Background(), r)
-        if err == nil && len(resp.Rows) > 0 && len(resp.Rows[0].Elements) > 0 {
-            element := resp.Rows[0].Elements[0]
-            if element.Status == "OK" {
-                result.Distance = float64(element.Distance.Meters) / 1000
-                result.Duration = int64(element.Duration.Seconds())
+        if result.Source == DistanceSourceEstimate {
+            if strict {
+                attempts = append(attempts, RoutingAttempt{
+                    Provider: p.Name(),
+                    Status:   RoutingStatusEstimateOnly,
+                    Error:    "straight-line estimate rejected in strict mode",
+                })
+                continue
             }
+            // Tahmin, zincirde daha önce başarısız olan sağlayıcıların durumunu taşır
+            result.UpstreamStatus = estimateStatus(attempts)
         }
+
+        result.Provider = p.Name()
+        result.Confidence = distanceConfidence(result.Source)
+        result.Fallbacks = attempts
+        return result, nil
     }
 
-    return result, nil
+    if strict {
+        return nil, &NoRoadDistanceError{Attempts: attempts}
+    }
+
+    estimate, _ := NewHaversineRoutingProvider().Distance(ctx, origin, destination, opts)
+    estimate.Provider = "haversine"
+    estimate.UpstreamStatus = estimateStatus(attempts)
+    estimate.Confidence = confidenceEstimate
+    estimate.Fallbacks = attempts
+    return estimate, nil
 }
 
-func (s *MapService) GetRoute(origin, destination maps.LatLng) ([]maps.LatLng, error) {
-    r := &maps.DirectionsRequest{
-        Origin:      origin.String(),
-        Destination: destination.String(),
-        Mode:        maps.TravelModeDriving,
+// estimateStatus, tahmine düşülmesine neden olan son yukarı akış durumudur
+func estimateStatus(attempts []RoutingAttempt) string {
+    if len(attempts) == 0 {
+        return RoutingStatusEstimateOnly
     }
+    return attempts[len(attempts)-1].Status
+}
 
-    resp, _, err := s.client.Directions(context.Background(), r)
+func distanceConfidence(source string) float64 {
+    switch source {
+    case DistanceSourceEstimate:
+        return confidenceEstimate
+    case DistanceSourceCache:
+        return confidenceCache
+    default:
+        return confidenceLive
+    }
+}
+
+// GetRoute, sürüş rotasını tam çizgi, adım talimatları ve sınırlarla döndürür
+func (s *MapService) GetRoute(ctx context.Context, origin, destination maps.LatLng, opts TravelOptions) (*RouteResult, error) {
+    var lastErr error
+    for _, p := range s.providers {
+        route, err := p.Route(ctx, origin, destination, opts)
+        if err == nil {
+            route.Provider = p.Name()
+            for _, alt := range route.Alternatives {
+                alt.Provider = p.Name()
+            }
+            return route, nil
+        }
+        log.Printf("%s rota hesaplayamadı, sıradaki sağlayıcı deneniyor: %v", p.Name(), err)
+        lastErr = err
+    }
+    return nil, fmt.Errorf("no routing provider answered: %v", lastErr)
+}
+
+// GetRoutePath, rotanın genel bakış çizgisini, toplam mesafesini ve süresini döndürür
+func (s *MapService) GetRoutePath(ctx context.Context, origin, destination maps.LatLng) (*RoutePath, error) {
+    route, err := s.GetRoute(ctx, origin, destination, TravelOptions{})
     if err != nil {
         return nil, err
     }
 
-    if len(resp) == 0 {
-        return nil, fmt.Errorf("no route found")
+    return &RoutePath{
+        Points:          route.Polyline.Points,
+        DistanceKM:      float64(route.DistanceMeters) / 1000,
+        DurationSeconds: route.DurationSeconds,
+        Provider:        route.Provider,
+    }, nil
+}
+
+// Matris hücresinin hesaplanma şekli
+const (
+    MatrixCellRoad         = "road"          // yönlendirme sağlayıcısından sürüş mesafesi
+    MatrixCellStraightLine = "straight_line" // kuş uçuşu tahmin
+)
+
+type MatrixCell struct {
+    DistanceKM      float64 `json:"distance_km"`
+    DurationSeconds int64   `json:"duration_seconds"`
+    Kind            string  `json:"kind"`
+    Provider        string  `json:"provider"`
+}
+
+// GetDistanceMatrix, her başlangıçtan her varışa mesafeyi döndürür. Toplu
+// hesaplayabilen sağlayıcılar zincir sırasıyla denenir; hiçbirinin
+// dolduramadığı hücreler kuş uçuşu tahminle doldurulur.
+func (s *MapService) GetDistanceMatrix(ctx context.Context, origins, destinations []maps.LatLng) [][]MatrixCell {
+    cells := make([][]MatrixCell, len(origins))
+    for i := range cells {
+        cells[i] = make([]MatrixCell, len(destinations))
     }
+    missing := len(origins) * len(destinations)
+
+    for _, p := range s.providers {
+        mp, ok := p.(MatrixRoutingProvider)
+        if !ok || missing == 0 {
+            continue
+        }
+        grid, err := mp.Matrix(ctx, origins, destinations)
+        if err != nil {
+            log.Printf("%s mesafe matrisi hesaplayamadı, sıradaki sağlayıcı deneniyor: %v", p.Name(), err)
+            continue
+        }
 
-    var route []maps.LatLng
-    for _, leg := range resp[0].Legs {
-        for _, step := range leg.Steps {
-            route = append(route, step.StartLocation)
-            route = append(route, step.EndLocation)
+        for i := range cells {
+            for j := range cells[i] {
+                if cells[i][j].Kind != "" || grid[i][j] == nil {
+                    continue
+                }
+                cells[i][j] = MatrixCell{
+                    DistanceKM:      grid[i][j].Distance,
+                    DurationSeconds: grid[i][j].Duration,
+                    Kind:            MatrixCellRoad,
+                    Provider:        p.Name(),
+                }
+                missing--
+            }
         }
     }
 
-    return route, nil
-} 
\ No newline at end of file
+    if missing > 0 {
+        estimator := NewHaversineRoutingProvider()
+        for i, origin := range origins {
+            for j, destination := range destinations {
+                if cells[i][j].Kind != "" {
+                    continue
+                }
+                estimate, _ := estimator.Distance(ctx, origin, destination, TravelOptions{})
+                cells[i][j] = MatrixCell{
+                    DistanceKM:      estimate.Distance,
+                    DurationSeconds: estimate.Duration,
+                    Kind:            MatrixCellStraightLine,
+                    Provider:        estimator.Name(),
+                }
+            }
+        }
+    }
+    return cells
+}
@@ -0,0 +1,154 @@
+package services
+
+import (
+    "context"
+    "errors"
+    "reflect"
+    "testing"
+
+    "googlemaps.github.io/maps"
+)
+
+// fakeRoutingProvider, sabit bir mesafe sonucu ya da hata döndürür
+type fakeRoutingProvider struct {
+    name   string
+    source string
+    err    error
+}
+
+func (p *fakeRoutingProvider) Name() string { return p.name }
+
+func (p *fakeRoutingProvider) Route(ctx context.Context, origin, destination maps.LatLng, opts TravelOptions) (*RouteResult, error) {
+    return nil, errors.New("not implemented")
+}
+
+func (p *fakeRoutingProvider) Distance(ctx context.Context, origin, destination maps.LatLng, opts TravelOptions) (*DistanceResult, error) {
+    if p.err != nil {
+        return nil, p.err
+    }
+    return &DistanceResult{Distance: 450, Duration: 18000, Source: p.source, UpstreamStatus: "OK"}, nil
+}
+
+func TestGetDistanceSourceAndConfidence(t *testing.T) {
+    overLimit := &fakeRoutingProvider{name: "google", err: &RoutingError{Status: "OVER_QUERY_LIMIT", Err: errors.New("quota")}}
+    unreachable := &fakeRoutingProvider{name: "osrm", err: errors.New("connection refused")}
+    live := &fakeRoutingProvider{name: "osrm", source: "osrm"}
+    cached := &fakeRoutingProvider{name: "google", source: DistanceSourceCache}
+
+    tests := []struct {
+        name           string
+        providers      []RoutingProvider
+        strict         bool
+        wantProvider   string
+        wantSource     string
+        wantStatus     string
+        wantConfidence float64
+        wantAttempts   []RoutingAttempt
+    }{
+        {
+            name:           "live result",
+            providers:      []RoutingProvider{live},
+            wantProvider:   "osrm",
+            wantSource:     "osrm",
+            wantStatus:     "OK",
+            wantConfidence: confidenceLive,
+        },
+        {
+            name:           "cached result",
+            providers:      []RoutingProvider{cached},
+            wantProvider:   "google",
+            wantSource:     DistanceSourceCache,
+            wantStatus:     "OK",
+            wantConfidence: confidenceCache,
+        },
+        {
+            name:           "fallback after upstream error",
+            providers:      []RoutingProvider{overLimit, live},
+            strict:         true,
+            wantProvider:   "osrm",
+            wantSource:     "osrm",
+            wantStatus:     "OK",
+            wantConfidence: confidenceLive,
+            wantAttempts:   []RoutingAttempt{{Provider: "google", Status: "OVER_QUERY_LIMIT", Error: "OVER_QUERY_LIMIT: quota"}},
+        },
+        {
+            name:           "estimate carries upstream status",
+            providers:      []RoutingProvider{overLimit, NewHaversineRoutingProvider()},
+            wantProvider:   "haversine",
+            wantSource:     DistanceSourceEstimate,
+            wantStatus:     "OVER_QUERY_LIMIT",
+            wantConfidence: confidenceEstimate,
+            wantAttempts:   []RoutingAttempt{{Provider: "google", Status: "OVER_QUERY_LIMIT", Error: "OVER_QUERY_LIMIT: quota"}},
+        },
+        {
+            name:           "estimate only",
+            providers:      []RoutingProvider{NewHaversineRoutingProvider()},
+            wantProvider:   "haversine",
+            wantSource:     DistanceSourceEstimate,
+            wantStatus:     RoutingStatusEstimateOnly,
+            wantConfidence: confidenceEstimate,
+        },
+        {
+            name:           "all providers fail",
+            providers:      []RoutingProvider{unreachable},
+            wantProvider:   "haversine",
+            wantSource:     DistanceSourceEstimate,
+            wantStatus:     RoutingStatusRequestFailed,
+            wantConfidence: confidenceEstimate,
+            wantAttempts:   []RoutingAttempt{{Provider: "osrm", Status: RoutingStatusRequestFailed, Error: "connection refused"}},
+        },
+    }
+
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            s := NewMapService(tt.providers...)
+            result, err := s.GetDistance(context.Background(), testOrigin, testDestination, TravelOptions{}, tt.strict)
+            if err != nil {
+                t.Fatal(err)
+            }
+            if result.Provider != tt.wantProvider || result.Source != tt.wantSource || result.UpstreamStatus != tt.wantStatus {
+                t.Errorf("provider/source/status = %s/%s/%s, want %s/%s/%s",
+                    result.Provider, result.Source, result.UpstreamStatus, tt.wantProvider, tt.wantSource, tt.wantStatus)
+            }
+            if result.Confidence != tt.wantConfidence {
+                t.Errorf("confidence = %v, want %v", result.Confidence, tt.wantConfidence)
+            }
+            if !reflect.DeepEqual(result.Fallbacks, tt.wantAttempts) {
+                t.Errorf("fallbacks = %+v, want %+v", result.Fallbacks, tt.wantAttempts)
+            }
+        })
+    }
+}
+
+func TestGetDistanceStrictRejectsEstimates(t *testing.T) {
+    overLimit := &fakeRoutingProvider{name: "google", err: &RoutingError{Status: "OVER_QUERY_LIMIT", Err: errors.New("quota")}}
+
+    tests := []struct {
+        name       string
+        providers  []RoutingProvider
+        wantStatus []string
+    }{
+        {"haversine only", []RoutingProvider{NewHaversineRoutingProvider()}, []string{RoutingStatusEstimateOnly}},
+        {"upstream error then estimate", []RoutingProvider{overLimit, NewHaversineRoutingProvider()}, []string{"OVER_QUERY_LIMIT", RoutingStatusEstimateOnly}},
+    }
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            s := NewMapService(tt.providers...)
+            result, err := s.GetDistance(context.Background(), testOrigin, testDestination, TravelOptions{}, true)
+            var noRoad *NoRoadDistanceError
+            if !errors.As(err, &noRoad) {
+                t.Fatalf("result = %+v, err = %v, want NoRoadDistanceError", result, err)
+            }
+            var statuses []string
+            for _, attempt := range noRoad.Attempts {
+                statuses = append(statuses, attempt.Status)
+            }
+            if !reflect.DeepEqual(statuses, tt.wantStatus) {
+                t.Errorf("attempt statuses = %v, want %v", statuses, tt.wantStatus)
+            }
+            if last := noRoad.Attempts[len(noRoad.Attempts)-1]; last.Provider != "haversine" {
+                t.Errorf("last attempt provider = %s, want haversine", last.Provider)
+            }
+        })
+    }
+}
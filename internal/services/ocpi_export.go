@@ -0,0 +1,122 @@
+package services
+
+import (
+    "charging-stations-backend/internal/models"
+    "fmt"
+    "time"
+)
+
+// Katalog standartlarından OCPI standartlarına dönüşüm
+var ocpiExportStandards = map[string]string{
+    models.ConnectorType1:   "IEC_62196_T1",
+    models.ConnectorCCS1:    "IEC_62196_T1_COMBO",
+    models.ConnectorType2:   "IEC_62196_T2",
+    models.ConnectorCCS2:    "IEC_62196_T2_COMBO",
+    models.ConnectorCHAdeMO: "CHADEMO",
+    models.ConnectorGBTAC:   "GBT_AC",
+    models.ConnectorGBTDC:   "GBT_DC",
+    models.ConnectorTesla:   "TESLA_S",
+    models.ConnectorSchuko:  "DOMESTIC_F",
+}
+
+// StationToOCPILocation, katalogdaki bir istasyonu OCPI 2.2.1 Location'a çevirir.
+// Kaynak veri soket bazında durum taşımadığı için EVSE durumları istasyon
+// sayaçlarından dağıtılır: önce boş soketler, sonra arızalı ve kullanım dışı
+// olanlar işaretlenir, kalanlar şarj ediyor kabul edilir. Kaynak zaman bilgisi
+// taşımıyorsa last_updated, istasyonun katalogda en son değiştiği zamandır.
+//
+// Standardı OCPI'ye eşlenemeyen soketler için EVSE üretilmez; tip ve güç
+// uydurulmaz. Hiç EVSE'si kalmayan istasyonlar için ok false döner ve
+// istasyon dışa aktarılmaz.
+func StationToOCPILocation(station Station, countryCode, partyID string, fallbackUpdated time.Time) (location models.OCPILocation, ok bool) {
+    lastUpdated := fallbackUpdated
+    if station.LastUpdated != nil {
+        lastUpdated = *station.LastUpdated
+    } else if !station.ChangedAt.IsZero() {
+        lastUpdated = station.ChangedAt
+    }
+    lastUpdated = lastUpdated.UTC()
+
+    publish := true
+    location = models.OCPILocation{
+        CountryCode: countryCode,
+        PartyID:     partyID,
+        ID:          station.Key,
+        Publish:     &publish,
+        Name:        station.Name,
+        Address:     station.Name,
+        City:        "",
+        Country:     "TUR",
+        Coordinates: models.OCPIGeoLocation{
+            Latitude:  fmt.Sprintf("%.6f", station.Latitude),
+            Longitude: fmt.Sprintf("%.6f", station.Longitude),
+        },
+        TimeZone:    "Europe/Istanbul",
+        LastUpdated: lastUpdated,
+    }
+    if station.Brand != "" {
+        location.Operator = &models.OCPIBusinessDetails{Name: station.Brand}
+    }
+
+    available := map[string]int{
+        models.PowerTypeAC: station.ACAvailableSocketCount,
+        models.PowerTypeDC: station.DCAvailableSocketCount,
+    }
+    outOfOrder := station.ErrorDeviceCount
+    inoperative := station.UnavailableDeviceCount
+
+    for _, connector := range station.Connectors {
+        standard, known := ocpiExportStandards[connector.Standard]
+        if !known {
+            continue
+        }
+        for i := 0; i < connector.Count; i++ {
+            status := models.OCPIEVSECharging
+            switch {
+            case available[connector.PowerType] > 0:
+                status = models.OCPIEVSEAvailable
+                available[connector.PowerType]--
+            case outOfOrder > 0:
+                status = models.OCPIEVSEOutOfOrder
+                outOfOrder--
+            case inoperative > 0:
+                status = models.OCPIEVSEInoperative
+                inoperative--
+            }
+
+            uid := fmt.Sprintf("%s-%d", station.Key, len(location.EVSEs)+1)
+            location.EVSEs = append(location.EVSEs, models.OCPIEVSE{
+                UID:         uid,
+                Status:      status,
+                Connectors:  []models.OCPIConnector{ocpiExportConnector(connector, standard, lastUpdated)},
+                LastUpdated: lastUpdated,
+            })
+        }
+    }
+
+    return location, len(location.EVSEs) > 0
+}
+
+// ocpiExportConnector, güçten tipik gerilim ve akım değerlerini türetir
+func ocpiExportConnector(connector models.Connector, standard string, lastUpdated time.Time) models.OCPIConnector {
+    watts := int(connector.MaxPowerKW * 1000)
+    c := models.OCPIConnector{
+        ID:               "1",
+        Standard:         standard,
+        MaxElectricPower: watts,
+        LastUpdated:      lastUpdated,
+    }
+
+    if connector.PowerType == models.PowerTypeDC {
+        c.Format = "CABLE"
+        c.PowerType = "DC"
+        c.MaxVoltage = 400
+        c.MaxAmperage = watts / 400
+    } else {
+        c.Format = "SOCKET"
+        c.PowerType = "AC_3_PHASE"
+        c.MaxVoltage = 230
+        c.MaxAmperage = watts / (230 * 3)
+    }
+    return c
+}
@@ -0,0 +1,107 @@
+package services
+
+import (
+    "charging-stations-backend/internal/models"
+    "testing"
+    "time"
+)
+
+func TestStationToOCPILocationEVSEs(t *testing.T) {
+    station := Station{
+        Key:                    "trugo:7",
+        Name:                   "Trugo Ankara",
+        ACAvailableSocketCount: 1,
+        DCAvailableSocketCount: 1,
+        ErrorDeviceCount:       1,
+        TotalConnectorsCount:   5,
+        Connectors: []models.Connector{
+            {Standard: models.ConnectorCCS2, PowerType: models.PowerTypeDC, MaxPowerKW: 120, Count: 2},
+            {Standard: models.ConnectorUnknown, PowerType: models.PowerTypeDC, MaxPowerKW: 150, Count: 1},
+            {Standard: models.ConnectorType2, PowerType: models.PowerTypeAC, MaxPowerKW: 22, Count: 2},
+        },
+    }
+
+    location, ok := StationToOCPILocation(station, "TR", "TRG", time.Now())
+    if !ok || len(location.EVSEs) != 4 {
+        t.Fatalf("got %d EVSEs (ok=%v), want 4 without the unknown socket", len(location.EVSEs), ok)
+    }
+
+    statuses := make(map[string]int)
+    for _, evse := range location.EVSEs {
+        statuses[evse.Status]++
+        c := evse.Connectors[0]
+        switch c.Standard {
+        case "IEC_62196_T2_COMBO":
+            if c.PowerType != "DC" || c.MaxElectricPower != 120000 {
+                t.Errorf("CCS2 connector = %+v", c)
+            }
+        case "IEC_62196_T2":
+            if c.PowerType != "AC_3_PHASE" || c.MaxElectricPower != 22000 {
+                t.Errorf("Type2 connector = %+v", c)
+            }
+        default:
+            t.Errorf("unexpected connector %+v", c)
+        }
+    }
+    want := map[string]int{
+        models.OCPIEVSEAvailable:  2,
+        models.OCPIEVSEOutOfOrder: 1,
+        models.OCPIEVSECharging:   1,
+    }
+    for status, n := range want {
+        if statuses[status] != n {
+            t.Errorf("%s = %d, want %d (%v)", status, statuses[status], n, statuses)
+        }
+    }
+}
+
+func TestStationToOCPILocationWithoutKnownConnectors(t *testing.T) {
+    tests := []struct {
+        name       string
+        connectors []models.Connector
+    }{
+        {"unparsed connector list", nil},
+        {"unknown standard", []models.Connector{
+            {Standard: models.ConnectorUnknown, PowerType: models.PowerTypeDC, MaxPowerKW: 150, Count: 2},
+        }},
+    }
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            station := Station{Key: "trugo:8", TotalConnectorsCount: 2, DCAvailableSocketCount: 2, Connectors: tt.connectors}
+            if location, ok := StationToOCPILocation(station, "TR", "TRG", time.Now()); ok || len(location.EVSEs) != 0 {
+                t.Errorf("exported %d EVSEs, want location to be skipped", len(location.EVSEs))
+            }
+        })
+    }
+}
+
+func TestStampChangeTimesKeepsUnchangedStations(t *testing.T) {
+    first := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+    second := first.Add(5 * time.Minute)
+
+    stations := []Station{
+        {Key: "trugo:1", Name: "A", ACAvailableSocketCount: 1},
+        {Key: "trugo:2", Name: "B", ACAvailableSocketCount: 1},
+    }
+    stampChangeTimes(nil, stations, first)
+    prev := newStationCatalog(stations, first)
+
+    next := []Station{
+        {Key: "trugo:1", Name: "A", ACAvailableSocketCount: 1},
+        {Key: "trugo:2", Name: "B", ACAvailableSocketCount: 0},
+        {Key: "trugo:3", Name: "C"},
+    }
+    stampChangeTimes(prev, next, second)
+
+    want := []time.Time{first, second, second}
+    for i, station := range next {
+        if !station.ChangedAt.Equal(want[i]) {
+            t.Errorf("%s changed at %v, want %v", station.Key, station.ChangedAt, want[i])
+        }
+    }
+
+    location, _ := StationToOCPILocation(next[0], "TR", "TRG", second)
+    if !location.LastUpdated.Equal(first) {
+        t.Errorf("last_updated = %v, want %v", location.LastUpdated, first)
+    }
+}
@@ -0,0 +1,361 @@
+package services
+
+import (
+    "charging-stations-backend/internal/models"
+    "context"
+    "encoding/base64"
+    "encoding/json"
+    "fmt"
+    "io/ioutil"
+    "log"
+    "net/http"
+    "net/url"
+    "os"
+    "regexp"
+    "sort"
+    "strconv"
+    "strings"
+    "sync"
+    "time"
+)
+
+const (
+    ocpiPageLimit     = 100
+    ocpiFullSyncEvery = 24 * time.Hour
+    // Saat farklarına karşı artımlı senkronizasyonda bırakılan pay
+    ocpiSyncOverlap = time.Minute
+    // Hatalı bir hub'ın Link başlığıyla sonsuz döngüye sokmasına karşı üst sınır
+    ocpiMaxPages = 1000
+)
+
+// OCPIProvider, OCPI 2.2.1 Locations modülünü sunan bir CPO'dan istasyonları
+// okur. İlk çağrıda tüm lokasyonları, sonrakilerde yalnızca date_from ile
+// değişenleri çeker; günde bir kez tam senkronizasyon yapar.
+type OCPIProvider struct {
+    name         string
+    locationsURL string
+    token        string
+    client       *http.Client
+
+    mu           sync.Mutex
+    locations    map[string]models.OCPILocation
+    lastSync     time.Time
+    lastFullSync time.Time
+}
+
+func NewOCPIProvider(name, locationsURL, token string, client *http.Client) *OCPIProvider {
+    if client == nil {
+        client = &http.Client{Timeout: 30 * time.Second}
+    }
+    return &OCPIProvider{
+        name:         name,
+        locationsURL: locationsURL,
+        token:        token,
+        client:       client,
+        locations:    make(map[string]models.OCPILocation),
+    }
+}
+
+// OCPIProvidersFromEnv, OCPI_PROVIDERS içindeki her ad için OCPI_<AD>_URL ve
+// OCPI_<AD>_TOKEN değişkenlerinden sağlayıcı oluşturur (ör. OCPI_PROVIDERS=zes,esarj)
+func OCPIProvidersFromEnv() []StationProvider {
+    var providers []StationProvider
+    for _, name := range strings.Split(os.Getenv("OCPI_PROVIDERS"), ",") {
+        name = strings.ToLower(strings.TrimSpace(name))
+        if name == "" {
+            continue
+        }
+
+        prefix := "OCPI_" + strings.ToUpper(name) + "_"
+        locationsURL := os.Getenv(prefix + "URL")
+        if locationsURL == "" {
+            log.Printf("OCPI sağlayıcısı %s için %sURL tanımlı değil, atlanıyor", name, prefix)
+            continue
+        }
+        providers = append(providers, NewOCPIProvider(name, locationsURL, os.Getenv(prefix+"TOKEN"), nil))
+    }
+    return providers
+}
+
+func (p *OCPIProvider) Name() string {
+    return p.name
+}
+
+func (p *OCPIProvider) FetchStations(ctx context.Context) ([]Station, error) {
+    p.mu.Lock()
+    defer p.mu.Unlock()
+
+    syncStarted := time.Now()
+    full := p.lastSync.IsZero() || syncStarted.Sub(p.lastFullSync) > ocpiFullSyncEvery
+
+    var dateFrom time.Time
+    if !full {
+        dateFrom = p.lastSync.Add(-ocpiSyncOverlap)
+    }
+
+    locations, err := p.fetchLocations(ctx, dateFrom)
+    if err != nil {
+        return nil, err
+    }
+
+    if full {
+        p.locations = make(map[string]models.OCPILocation, len(locations))
+        p.lastFullSync = syncStarted
+    }
+    for _, location := range locations {
+        if location.Publish != nil && !*location.Publish {
+            delete(p.locations, ocpiLocationKey(location))
+            continue
+        }
+        p.locations[ocpiLocationKey(location)] = location
+    }
+    p.lastSync = syncStarted
+
+    stations := make([]Station, 0, len(p.locations))
+    for _, location := range p.locations {
+        station, ok := ocpiLocationToStation(location)
+        if ok {
+            stations = append(stations, station)
+        }
+    }
+    sort.Slice(stations, func(i, j int) bool {
+        return stations[i].Key < stations[j].Key
+    })
+
+    log.Printf("OCPI %s: %d lokasyon alındı, %d istasyon", p.name, len(locations), len(stations))
+    return stations, nil
+}
+
+// fetchLocations, Link başlığındaki "next" bağlantısını izleyerek tüm sayfaları okur
+func (p *OCPIProvider) fetchLocations(ctx context.Context, dateFrom time.Time) ([]models.OCPILocation, error) {
+    u, err := url.Parse(p.locationsURL)
+    if err != nil {
+        return nil, fmt.Errorf("geçersiz OCPI adresi: %v", err)
+    }
+    q := u.Query()
+    q.Set("limit", strconv.Itoa(ocpiPageLimit))
+    if !dateFrom.IsZero() {
+        q.Set("date_from", dateFrom.UTC().Format(time.RFC3339))
+    }
+    u.RawQuery = q.Encode()
+
+    var all []models.OCPILocation
+    visited := make(map[string]bool)
+    next := u.String()
+    for next != "" {
+        if visited[next] {
+            return nil, fmt.Errorf("OCPI sayfalama döngüsü: %s tekrar istendi", next)
+        }
+        if len(visited) >= ocpiMaxPages {
+            return nil, fmt.Errorf("OCPI sayfa sınırı aşıldı (%d)", ocpiMaxPages)
+        }
+        visited[next] = true
+
+        page, link, err := p.fetchPage(ctx, next)
+        if err != nil {
+            return nil, err
+        }
+        all = append(all, page...)
+        next = link
+    }
+    return all, nil
+}
+
+func (p *OCPIProvider) fetchPage(ctx context.Context, pageURL string) ([]models.OCPILocation, string, error) {
+    req, err := http.NewRequestWithContext(ctx, http.MethodGet, pageURL, nil)
+    if err != nil {
+        return nil, "", fmt.Errorf("OCPI isteği oluşturulamadı: %v", err)
+    }
+    if p.token != "" {
+        req.Header.Set("Authorization", "Token "+base64.StdEncoding.EncodeToString([]byte(p.token)))
+    }
+
+    resp, err := p.client.Do(req)
+    if err != nil {
+        return nil, "", fmt.Errorf("OCPI isteği hatası: %v", err)
+    }
+    defer resp.Body.Close()
+
+    body, err := ioutil.ReadAll(resp.Body)
+    if err != nil {
+        return nil, "", fmt.Errorf("OCPI yanıtı okunamadı: %v", err)
+    }
+    if resp.StatusCode != http.StatusOK {
+        return nil, "", fmt.Errorf("OCPI beklenmeyen durum kodu döndürdü: %d", resp.StatusCode)
+    }
+
+    var envelope models.OCPIResponse
+    if err := json.Unmarshal(body, &envelope); err != nil {
+        return nil, "", fmt.Errorf("OCPI JSON parse hatası: %v", err)
+    }
+    if envelope.StatusCode != models.OCPIStatusSuccess {
+        return nil, "", fmt.Errorf("OCPI hata döndürdü: %d %s", envelope.StatusCode, envelope.StatusMessage)
+    }
+
+    var locations []models.OCPILocation
+    if len(envelope.Data) > 0 {
+        if err := json.Unmarshal(envelope.Data, &locations); err != nil {
+            return nil, "", fmt.Errorf("OCPI lokasyonları parse edilemedi: %v", err)
+        }
+    }
+
+    next := parseNextLink(resp.Header.Get("Link"))
+    if next != "" {
+        // Link göreli olabilir
+        base, _ := url.Parse(pageURL)
+        if ref, err := url.Parse(next); err == nil {
+            next = base.ResolveReference(ref).String()
+        }
+    }
+    return locations, next, nil
+}
+
+var linkNextPattern = regexp.MustCompile(`<([^>]+)>\s*;[^,]*rel="?next"?`)
+
+// parseNextLink, `<https://...>; rel="next"` biçimindeki Link başlığından adresi çıkarır
+func parseNextLink(header string) string {
+    m := linkNextPattern.FindStringSubmatch(header)
+    if m == nil {
+        return ""
+    }
+    return m[1]
+}
+
+// ocpiLocationKey, lokasyonun benzersiz anahtarıdır. OCPI ID'leri yalnızca
+// country_code + party_id içinde tekildir; bir hub arkasındaki farklı CPO'lar
+// aynı ID'yi kullanabilir.
+func ocpiLocationKey(location models.OCPILocation) string {
+    return location.CountryCode + ":" + location.PartyID + ":" + location.ID
+}
+
+// ocpiLocationToStation, bir OCPI lokasyonunu istasyon modeline çevirir.
+// Her EVSE bir soket sayılır; soketin AC/DC tipi konnektörlerinden belirlenir.
+func ocpiLocationToStation(location models.OCPILocation) (Station, bool) {
+    lat, errLat := strconv.ParseFloat(location.Coordinates.Latitude, 64)
+    lon, errLon := strconv.ParseFloat(location.Coordinates.Longitude, 64)
+    if errLat != nil || errLon != nil {
+        log.Printf("OCPI lokasyonu %s geçersiz koordinat içeriyor, atlanıyor", location.ID)
+        return Station{}, false
+    }
+
+    lastUpdated := location.LastUpdated
+    station := Station{
+        Key:         ocpiLocationKey(location),
+        StationID:   location.ID,
+        Name:        location.Name,
+        Latitude:    lat,
+        Longitude:   lon,
+        LastUpdated: &lastUpdated,
+    }
+    if station.Name == "" {
+        station.Name = location.Address
+    }
+    if location.Operator != nil {
+        station.Brand = location.Operator.Name
+    }
+
+    type connectorKey struct {
+        standard  string
+        powerType string
+        power     float64
+    }
+    counts := make(map[connectorKey]int)
+    var order []connectorKey
+
+    for _, evse := range location.EVSEs {
+        if evse.Status == models.OCPIEVSERemoved {
+            continue
+        }
+
+        isDC := false
+        for _, c := range evse.Connectors {
+            key := connectorKey{
+                standard:  ocpiStandard(c.Standard),
+                powerType: ocpiPowerType(c.PowerType),
+                power:     ocpiConnectorPowerKW(c),
+            }
+            if key.powerType == models.PowerTypeDC {
+                isDC = true
+            }
+            if _, seen := counts[key]; !seen {
+                order = append(order, key)
+            }
+            counts[key]++
+            station.TotalConnectorsCount++
+        }
+
+        switch evse.Status {
+        case models.OCPIEVSEAvailable:
+            if isDC {
+                station.DCAvailableSocketCount++
+            } else {
+                station.ACAvailableSocketCount++
+            }
+        case models.OCPIEVSEOutOfOrder:
+            station.ErrorDeviceCount++
+        case models.OCPIEVSEInoperative, models.OCPIEVSEUnknown, models.OCPIEVSEPlanned:
+            station.UnavailableDeviceCount++
+        }
+    }
+
+    var parts []string
+    for _, key := range order {
+        station.Connectors = append(station.Connectors, models.Connector{
+            Standard:   key.standard,
+            PowerType:  key.powerType,
+            MaxPowerKW: key.power,
+            Count:      counts[key],
+        })
+        parts = append(parts, fmt.Sprintf("%s %gkW x%d", key.standard, key.power, counts[key]))
+    }
+    station.ConnectorList = strings.Join(parts, ", ")
+
+    return station, true
+}
+
+var ocpiStandards = map[string]string{
+    "IEC_62196_T1":       models.ConnectorType1,
+    "IEC_62196_T1_COMBO": models.ConnectorCCS1,
+    "IEC_62196_T2":       models.ConnectorType2,
+    "IEC_62196_T2_COMBO": models.ConnectorCCS2,
+    "CHADEMO":            models.ConnectorCHAdeMO,
+    "GBT_AC":             models.ConnectorGBTAC,
+    "GBT_DC":             models.ConnectorGBTDC,
+    "TESLA_R":            models.ConnectorTesla,
+    "TESLA_S":            models.ConnectorTesla,
+    "DOMESTIC_F":         models.ConnectorSchuko,
+}
+
+func ocpiStandard(standard string) string {
+    if s, ok := ocpiStandards[standard]; ok {
+        return s
+    }
+    if standard == "" {
+        return models.ConnectorUnknown
+    }
+    return standard
+}
+
+func ocpiPowerType(powerType string) string {
+    if powerType == "DC" {
+        return models.PowerTypeDC
+    }
+    return models.PowerTypeAC
+}
+
+// ocpiConnectorPowerKW, max_electric_power yoksa gerilim, akım ve faz
+// sayısından gücü hesaplar
+func ocpiConnectorPowerKW(c models.OCPIConnector) float64 {
+    if c.MaxElectricPower > 0 {
+        return float64(c.MaxElectricPower) / 1000
+    }
+
+    phases := 1.0
+    switch c.PowerType {
+    case "AC_2_PHASE":
+        phases = 2
+    case "AC_3_PHASE":
+        phases = 3
+    }
+    return float64(c.MaxVoltage) * float64(c.MaxAmperage) * phases / 1000
+}
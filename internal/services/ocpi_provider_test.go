@@ -0,0 +1,157 @@
+package services
+
+import (
+    "charging-stations-backend/internal/models"
+    "context"
+    "encoding/base64"
+    "encoding/json"
+    "fmt"
+    "net/http"
+    "net/http/httptest"
+    "strings"
+    "sync"
+    "testing"
+    "time"
+)
+
+func ocpiTestLocation(countryCode, partyID, id string, status string) models.OCPILocation {
+    return models.OCPILocation{
+        CountryCode: countryCode,
+        PartyID:     partyID,
+        ID:          id,
+        Name:        partyID + " " + id,
+        Coordinates: models.OCPIGeoLocation{Latitude: "41.0082", Longitude: "28.9784"},
+        EVSEs: []models.OCPIEVSE{{
+            UID:    id + "-1",
+            Status: status,
+            Connectors: []models.OCPIConnector{{
+                ID:               "1",
+                Standard:         "IEC_62196_T2_COMBO",
+                PowerType:        "DC",
+                MaxElectricPower: 120000,
+            }},
+        }},
+        LastUpdated: time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC),
+    }
+}
+
+func writeOCPIPage(w http.ResponseWriter, locations []models.OCPILocation) {
+    data, _ := json.Marshal(locations)
+    json.NewEncoder(w).Encode(models.OCPIResponse{
+        Data:       data,
+        StatusCode: models.OCPIStatusSuccess,
+        Timestamp:  time.Now(),
+    })
+}
+
+func TestOCPIProviderFetchStations(t *testing.T) {
+    var mu sync.Mutex
+    var queries []string
+
+    server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        want := "Token " + base64.StdEncoding.EncodeToString([]byte("secret"))
+        if got := r.Header.Get("Authorization"); got != want {
+            t.Errorf("Authorization = %q, want %q", got, want)
+        }
+        mu.Lock()
+        queries = append(queries, r.URL.RawQuery)
+        mu.Unlock()
+
+        if r.URL.Query().Get("date_from") != "" {
+            // Artımlı senkronizasyon: bir lokasyon yayından kalkar
+            hidden := ocpiTestLocation("TR", "ZES", "1", models.OCPIEVSEAvailable)
+            publish := false
+            hidden.Publish = &publish
+            writeOCPIPage(w, []models.OCPILocation{hidden})
+            return
+        }
+
+        switch r.URL.Query().Get("offset") {
+        case "":
+            // Göreli next bağlantısı
+            w.Header().Set("Link", `</ocpi/locations?limit=100&offset=1>; rel="next"`)
+            writeOCPIPage(w, []models.OCPILocation{
+                ocpiTestLocation("TR", "ZES", "1", models.OCPIEVSEAvailable),
+            })
+        case "1":
+            // Aynı hub arkasında aynı ID'yi kullanan başka bir CPO
+            writeOCPIPage(w, []models.OCPILocation{
+                ocpiTestLocation("TR", "ESJ", "1", models.OCPIEVSEOutOfOrder),
+            })
+        default:
+            t.Errorf("unexpected offset %q", r.URL.Query().Get("offset"))
+        }
+    }))
+    defer server.Close()
+
+    provider := NewOCPIProvider("hub", server.URL+"/ocpi/locations", "secret", server.Client())
+
+    stations, err := provider.FetchStations(context.Background())
+    if err != nil {
+        t.Fatal(err)
+    }
+    if len(stations) != 2 {
+        t.Fatalf("got %d stations, want 2", len(stations))
+    }
+    if stations[0].Key != "TR:ESJ:1" || stations[1].Key != "TR:ZES:1" {
+        t.Fatalf("keys = %q, %q", stations[0].Key, stations[1].Key)
+    }
+    if stations[0].ErrorDeviceCount != 1 || stations[1].DCAvailableSocketCount != 1 {
+        t.Errorf("unexpected socket counts: %+v", stations)
+    }
+    if c := stations[1].Connectors; len(c) != 1 || c[0].Standard != models.ConnectorCCS2 || c[0].MaxPowerKW != 120 {
+        t.Errorf("unexpected connectors: %+v", c)
+    }
+
+    stations, err = provider.FetchStations(context.Background())
+    if err != nil {
+        t.Fatal(err)
+    }
+    if len(stations) != 1 || stations[0].Key != "TR:ESJ:1" {
+        t.Fatalf("after incremental sync got %+v", stations)
+    }
+
+    mu.Lock()
+    defer mu.Unlock()
+    if len(queries) != 3 || !strings.Contains(queries[2], "date_from=") {
+        t.Errorf("queries = %q", queries)
+    }
+}
+
+func TestOCPIProviderStopsPaginationLoop(t *testing.T) {
+    var requests int
+    server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        requests++
+        // Her sayfa kendini next olarak gösterir
+        w.Header().Set("Link", fmt.Sprintf("<%s>; rel=\"next\"", r.URL.String()))
+        writeOCPIPage(w, []models.OCPILocation{
+            ocpiTestLocation("TR", "ZES", "1", models.OCPIEVSEAvailable),
+        })
+    }))
+    defer server.Close()
+
+    provider := NewOCPIProvider("hub", server.URL+"/ocpi/locations", "", server.Client())
+    if _, err := provider.FetchStations(context.Background()); err == nil {
+        t.Fatal("expected pagination loop error")
+    }
+    if requests != 1 {
+        t.Errorf("got %d requests, want 1", requests)
+    }
+}
+
+func TestParseNextLink(t *testing.T) {
+    tests := []struct {
+        header string
+        want   string
+    }{
+        {`<https://hub.example/ocpi/locations?offset=100>; rel="next"`, "https://hub.example/ocpi/locations?offset=100"},
+        {`<https://hub.example/a>; rel=next`, "https://hub.example/a"},
+        {`<https://hub.example/prev>; rel="prev"`, ""},
+        {"", ""},
+    }
+    for _, tt := range tests {
+        if got := parseNextLink(tt.header); got != tt.want {
+            t.Errorf("parseNextLink(%q) = %q, want %q", tt.header, got, tt.want)
+        }
+    }
+}
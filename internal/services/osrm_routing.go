@@ -0,0 +1,296 @@
+package services
+
+import (
+    "context"
+    "encoding/json"
+    "fmt"
+    "io/ioutil"
+    "net/http"
+    "strconv"
+    "strings"
+
+    "googlemaps.github.io/maps"
+)
+
+// OSRMRoutingProvider, kendi sunucumuzda çalışan bir OSRM HTTP API'sini kullanır
+type OSRMRoutingProvider struct {
+    baseURL string
+    profile string
+    client  *http.Client
+}
+
+func NewOSRMRoutingProvider(baseURL, profile string, client *http.Client) *OSRMRoutingProvider {
+    if profile == "" {
+        profile = "driving"
+    }
+    if client == nil {
+        client = &http.Client{Timeout: routingRequestTimeout}
+    }
+    return &OSRMRoutingProvider{
+        baseURL: strings.TrimRight(baseURL, "/"),
+        profile: profile,
+        client:  client,
+    }
+}
+
+// OSRM route servisi yanıtı (geometries=polyline, hassasiyet 1e5)
+type osrmResponse struct {
+    Code    string      `json:"code"`
+    Message string      `json:"message"`
+    Routes  []osrmRoute `json:"routes"`
+}
+
+type osrmRoute struct {
+    Geometry string    `json:"geometry"`
+    Distance float64   `json:"distance"` // metre
+    Duration float64   `json:"duration"` // saniye
+    Legs     []osrmLeg `json:"legs"`
+}
+
+type osrmLeg struct {
+    Summary  string     `json:"summary"`
+    Distance float64    `json:"distance"`
+    Duration float64    `json:"duration"`
+    Steps    []osrmStep `json:"steps"`
+}
+
+type osrmStep struct {
+    Geometry string  `json:"geometry"`
+    Name     string  `json:"name"`
+    Distance float64 `json:"distance"`
+    Duration float64 `json:"duration"`
+    Maneuver struct {
+        Type     string     `json:"type"`
+        Modifier string     `json:"modifier"`
+        Location [2]float64 `json:"location"` // [boylam, enlem]
+    } `json:"maneuver"`
+}
+
+// OSRM table servisi yanıtı; erişilemeyen hücreler null döner
+type osrmTableResponse struct {
+    Code      string       `json:"code"`
+    Message   string       `json:"message"`
+    Distances [][]*float64 `json:"distances"` // metre
+    Durations [][]*float64 `json:"durations"` // saniye
+}
+
+func (p *OSRMRoutingProvider) Name() string {
+    return "osrm"
+}
+
+// Route, OSRM rotasını döndürür. OSRM trafik ve saat bilgisi kullanmaz;
+// istenirse alternatif rotalar eklenir.
+func (p *OSRMRoutingProvider) Route(ctx context.Context, origin, destination maps.LatLng, opts TravelOptions) (*RouteResult, error) {
+    routes, err := p.fetchRoutes(ctx, origin, destination, true, opts.Alternatives)
+    if err != nil {
+        return nil, err
+    }
+
+    var result *RouteResult
+    for i := range routes {
+        converted, err := osrmRouteResult(routes[i])
+        if err != nil {
+            return nil, err
+        }
+        if result == nil {
+            result = converted
+        } else {
+            result.Alternatives = append(result.Alternatives, converted)
+        }
+    }
+    if warning := opts.trafficUnavailableWarning(p.Name()); warning != "" {
+        result.Warnings = append(result.Warnings, warning)
+    }
+    return result, nil
+}
+
+func osrmRouteResult(route osrmRoute) (*RouteResult, error) {
+    overview, err := maps.DecodePolyline(route.Geometry)
+    if err != nil {
+        return nil, fmt.Errorf("OSRM rota çizgisi çözülemedi: %v", err)
+    }
+
+    result := &RouteResult{
+        DistanceMeters:  int(route.Distance),
+        DurationSeconds: int64(route.Duration),
+        Bounds:          boundsOf(overview),
+        Polyline:        RouteLine{Points: overview},
+        Legs:            make([]RouteLeg, 0, len(route.Legs)),
+    }
+
+    for _, leg := range route.Legs {
+        out := RouteLeg{
+            DistanceMeters:  int(leg.Distance),
+            DurationSeconds: int64(leg.Duration),
+            Steps:           make([]RouteStep, 0, len(leg.Steps)),
+        }
+        for _, step := range leg.Steps {
+            points, err := maps.DecodePolyline(step.Geometry)
+            if err != nil {
+                return nil, fmt.Errorf("OSRM adım çizgisi çözülemedi: %v", err)
+            }
+            start := maps.LatLng{Lat: step.Maneuver.Location[1], Lng: step.Maneuver.Location[0]}
+            end := start
+            if len(points) > 0 {
+                end = points[len(points)-1]
+            }
+            out.Steps = append(out.Steps, RouteStep{
+                Instruction:     osrmInstruction(step),
+                DistanceMeters:  int(step.Distance),
+                DurationSeconds: int64(step.Duration),
+                Start:           start,
+                End:             end,
+                Polyline:        RouteLine{Points: points},
+            })
+        }
+        if len(out.Steps) > 0 {
+            out.Start = out.Steps[0].Start
+            out.End = out.Steps[len(out.Steps)-1].End
+        }
+        if result.Summary == "" {
+            result.Summary = leg.Summary
+        }
+        result.Legs = append(result.Legs, out)
+    }
+    return result, nil
+}
+
+func (p *OSRMRoutingProvider) Distance(ctx context.Context, origin, destination maps.LatLng, opts TravelOptions) (*DistanceResult, error) {
+    routes, err := p.fetchRoutes(ctx, origin, destination, false, false)
+    if err != nil {
+        return nil, err
+    }
+    route := routes[0]
+    result := &DistanceResult{
+        Distance:       route.Distance / 1000,
+        Duration:       int64(route.Duration),
+        Source:         p.Name(),
+        UpstreamStatus: "Ok",
+    }
+    if warning := opts.trafficUnavailableWarning(p.Name()); warning != "" {
+        result.Warnings = append(result.Warnings, warning)
+    }
+    return result, nil
+}
+
+// Matrix, tüm başlangıç ve varışları tek bir table isteğiyle hesaplar
+func (p *OSRMRoutingProvider) Matrix(ctx context.Context, origins, destinations []maps.LatLng) ([][]*DistanceResult, error) {
+    coords := make([]string, 0, len(origins)+len(destinations))
+    sources := make([]string, len(origins))
+    targets := make([]string, len(destinations))
+    for i, o := range origins {
+        coords = append(coords, fmt.Sprintf("%f,%f", o.Lng, o.Lat))
+        sources[i] = strconv.Itoa(i)
+    }
+    for j, d := range destinations {
+        coords = append(coords, fmt.Sprintf("%f,%f", d.Lng, d.Lat))
+        targets[j] = strconv.Itoa(len(origins) + j)
+    }
+
+    tableURL := fmt.Sprintf("%s/table/v1/%s/%s?sources=%s&destinations=%s&annotations=distance,duration",
+        p.baseURL, p.profile, strings.Join(coords, ";"), strings.Join(sources, ";"), strings.Join(targets, ";"))
+
+    var result osrmTableResponse
+    if err := p.getJSON(ctx, tableURL, &result); err != nil {
+        return nil, err
+    }
+    if result.Code != "Ok" {
+        return nil, fmt.Errorf("OSRM hata döndürdü: %s %s", result.Code, result.Message)
+    }
+
+    grid := newDistanceGrid(len(origins), len(destinations))
+    for i := range grid {
+        if i >= len(result.Distances) || i >= len(result.Durations) {
+            break
+        }
+        for j := range grid[i] {
+            if j >= len(result.Distances[i]) || j >= len(result.Durations[i]) {
+                break
+            }
+            distance, duration := result.Distances[i][j], result.Durations[i][j]
+            if distance == nil || duration == nil {
+                continue
+            }
+            grid[i][j] = &DistanceResult{
+                Distance:       *distance / 1000,
+                Duration:       int64(*duration),
+                Source:         p.Name(),
+                UpstreamStatus: result.Code,
+            }
+        }
+    }
+    return grid, nil
+}
+
+// fetchRoutes, OSRM'den rotaları alır; full false ise çizgi ve adımlar istenmez
+func (p *OSRMRoutingProvider) fetchRoutes(ctx context.Context, origin, destination maps.LatLng, full, alternatives bool) ([]osrmRoute, error) {
+    query := "overview=false&steps=false"
+    if full {
+        query = "overview=full&geometries=polyline&steps=true"
+    }
+    if alternatives {
+        query += "&alternatives=true"
+    }
+    // OSRM koordinatları boylam,enlem sırasıyla bekler
+    routeURL := fmt.Sprintf("%s/route/v1/%s/%f,%f;%f,%f?%s",
+        p.baseURL, p.profile, origin.Lng, origin.Lat, destination.Lng, destination.Lat, query)
+
+    var result osrmResponse
+    if err := p.getJSON(ctx, routeURL, &result); err != nil {
+        return nil, err
+    }
+    if result.Code != "Ok" {
+        return nil, &RoutingError{Status: result.Code, Err: fmt.Errorf("OSRM hata döndürdü: %s", result.Message)}
+    }
+    if len(result.Routes) == 0 {
+        return nil, &RoutingError{Status: "NoRoute", Err: fmt.Errorf("no route found")}
+    }
+    return result.Routes, nil
+}
+
+// getJSON, OSRM'ye GET isteği atar ve yanıtı çözer. OSRM hata durumlarında da
+// gövdede code/message döndürdüğü için durum kodu ayrıca kontrol edilmez.
+func (p *OSRMRoutingProvider) getJSON(ctx context.Context, requestURL string, out interface{}) error {
+    req, err := http.NewRequestWithContext(ctx, http.MethodGet, requestURL, nil)
+    if err != nil {
+        return fmt.Errorf("OSRM isteği oluşturulamadı: %v", err)
+    }
+
+    resp, err := p.client.Do(req)
+    if err != nil {
+        return fmt.Errorf("OSRM isteği hatası: %v", err)
+    }
+    defer resp.Body.Close()
+
+    body, err := ioutil.ReadAll(resp.Body)
+    if err != nil {
+        return fmt.Errorf("OSRM yanıtı okunamadı: %v", err)
+    }
+    if err := json.Unmarshal(body, out); err != nil {
+        return fmt.Errorf("OSRM beklenmeyen yanıt döndürdü (%d): %v", resp.StatusCode, err)
+    }
+    return nil
+}
+
+// osrmInstruction, OSRM manevrasından okunabilir bir talimat üretir
+// (ör. "Turn left onto Atatürk Bulvarı")
+func osrmInstruction(step osrmStep) string {
+    var parts []string
+    switch step.Maneuver.Type {
+    case "depart":
+        parts = append(parts, "Head")
+    case "arrive":
+        return "Arrive at destination"
+    case "":
+        parts = append(parts, "Continue")
+    default:
+        parts = append(parts, strings.ToUpper(step.Maneuver.Type[:1])+step.Maneuver.Type[1:])
+    }
+    if step.Maneuver.Modifier != "" {
+        parts = append(parts, step.Maneuver.Modifier)
+    }
+    if step.Name != "" {
+        parts = append(parts, "onto", step.Name)
+    }
+    return strings.Join(parts, " ")
+}
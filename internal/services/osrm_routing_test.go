@@ -0,0 +1,39 @@
+package services
+
+import (
+    "context"
+    "net/http"
+    "net/http/httptest"
+    "testing"
+    "time"
+)
+
+func TestOSRMDistanceTrafficWarning(t *testing.T) {
+    server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        w.Write([]byte(`{"code":"Ok","routes":[{"distance":12300,"duration":900}]}`))
+    }))
+    defer server.Close()
+    p := NewOSRMRoutingProvider(server.URL, "", server.Client())
+
+    tests := []struct {
+        name         string
+        opts         TravelOptions
+        wantWarnings int
+    }{
+        {"no traffic requested", TravelOptions{}, 0},
+        {"depart now", TravelOptions{DepartNow: true}, 1},
+        {"departure time", TravelOptions{DepartureTime: time.Now().Add(time.Hour)}, 1},
+        {"arrival time", TravelOptions{ArrivalTime: time.Now().Add(time.Hour)}, 0},
+    }
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            result, err := p.Distance(context.Background(), testOrigin, testDestination, tt.opts)
+            if err != nil {
+                t.Fatal(err)
+            }
+            if result.Distance != 12.3 || len(result.Warnings) != tt.wantWarnings {
+                t.Errorf("result = %+v, want %d warnings", result, tt.wantWarnings)
+            }
+        })
+    }
+}
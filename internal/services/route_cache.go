@@ -0,0 +1,230 @@
+package services
+
+import (
+    "container/list"
+    "context"
+    "database/sql"
+    "encoding/json"
+    "fmt"
+    "log"
+    "strings"
+    "sync"
+    "sync/atomic"
+    "time"
+
+    "googlemaps.github.io/maps"
+)
+
+const (
+    defaultRouteCacheTTL  = 6 * time.Hour
+    defaultRouteCacheSize = 10000
+    // Koordinatlar 4 ondalığa (~11 m) yuvarlanır; aynı noktadan art arda
+    // gelen istekler aynı kaydı kullanır
+    routeCachePrecision = 4
+)
+
+// Önbellekteki sonuç türleri
+const (
+    routeCacheDirections = "directions"
+    routeCacheDistance   = "distance"
+)
+
+// RouteCacheStats, önbelleğin isabet/ıska sayaçlarıdır
+type RouteCacheStats struct {
+    Entries    int    `json:"entries"`
+    MaxEntries int    `json:"max_entries"`
+    TTLSeconds int    `json:"ttl_seconds"`
+    Persistent bool   `json:"persistent"`
+    Hits       uint64 `json:"hits"`
+    DBHits     uint64 `json:"db_hits"`
+    Misses     uint64 `json:"misses"`
+    Evictions  uint64 `json:"evictions"`
+}
+
+type routeCacheEntry struct {
+    key       string
+    payload   []byte
+    expiresAt time.Time
+}
+
+// RouteCache, ücretli harita API sonuçlarını yuvarlanmış başlangıç/varış ve
+// yolculuk moduna göre saklar. Bellekte LRU olarak tutulur; db verilirse
+// kayıtlar Postgres'e de yazılır ve yeniden başlatmadan sonra oradan okunur.
+// Değerler JSON olarak saklandığı için her isabet bağımsız bir kopya döndürür.
+type RouteCache struct {
+    db         *sql.DB
+    ttl        time.Duration
+    maxEntries int
+
+    mu        sync.Mutex
+    entries   map[string]*list.Element
+    lru       *list.List // ön taraf en son kullanılan
+    lastPrune time.Time
+
+    hits      atomic.Uint64
+    dbHits    atomic.Uint64
+    misses    atomic.Uint64
+    evictions atomic.Uint64
+}
+
+// NewRouteCache, db nil ise yalnızca bellekte çalışan bir önbellek oluşturur
+func NewRouteCache(db *sql.DB, ttl time.Duration, maxEntries int) *RouteCache {
+    if ttl <= 0 {
+        ttl = defaultRouteCacheTTL
+    }
+    if maxEntries <= 0 {
+        maxEntries = defaultRouteCacheSize
+    }
+    return &RouteCache{
+        db:         db,
+        ttl:        ttl,
+        maxEntries: maxEntries,
+        entries:    make(map[string]*list.Element),
+        lru:        list.New(),
+    }
+}
+
+// routeCacheKey, sonuç türü, mod ve yuvarlanmış koordinatlardan anahtar üretir
+// (ör. "distance|driving|41.0082,28.9784|39.9334,32.8597")
+func routeCacheKey(kind, mode string, origin, destination maps.LatLng, extra ...string) string {
+    parts := []string{
+        kind,
+        mode,
+        fmt.Sprintf("%.*f,%.*f", routeCachePrecision, origin.Lat, routeCachePrecision, origin.Lng),
+        fmt.Sprintf("%.*f,%.*f", routeCachePrecision, destination.Lat, routeCachePrecision, destination.Lng),
+    }
+    return strings.Join(append(parts, extra...), "|")
+}
+
+// Get, anahtardaki değeri out'a çözer. Bellekte yoksa ve kalıcılık açıksa
+// Postgres'e bakılır; bulunan kayıt belleğe de alınır.
+func (c *RouteCache) Get(ctx context.Context, key string, out interface{}) bool {
+    if c == nil {
+        return false
+    }
+    now := time.Now()
+
+    c.mu.Lock()
+    var payload []byte
+    if el, ok := c.entries[key]; ok {
+        entry := el.Value.(*routeCacheEntry)
+        if now.Before(entry.expiresAt) {
+            c.lru.MoveToFront(el)
+            payload = entry.payload
+        } else {
+            c.removeLocked(el)
+        }
+    }
+    c.mu.Unlock()
+
+    if payload != nil && json.Unmarshal(payload, out) == nil {
+        c.hits.Add(1)
+        return true
+    }
+
+    if c.db != nil {
+        var expiresAt time.Time
+        err := c.db.QueryRowContext(ctx,
+            `SELECT payload::text, expires_at FROM route_cache WHERE cache_key = $1 AND expires_at > $2`,
+            key, now,
+        ).Scan(&payload, &expiresAt)
+        if err == nil && json.Unmarshal(payload, out) == nil {
+            c.storeLocal(key, payload, expiresAt)
+            c.dbHits.Add(1)
+            return true
+        }
+        if err != nil && err != sql.ErrNoRows {
+            log.Printf("Rota önbelleği okunamadı: %v", err)
+        }
+    }
+
+    c.misses.Add(1)
+    return false
+}
+
+// Set, değeri TTL süresince saklar
+func (c *RouteCache) Set(ctx context.Context, key string, value interface{}) {
+    if c == nil {
+        return
+    }
+    payload, err := json.Marshal(value)
+    if err != nil {
+        log.Printf("Rota önbelleğine yazılamadı: %v", err)
+        return
+    }
+
+    now := time.Now()
+    expiresAt := now.Add(c.ttl)
+    c.storeLocal(key, payload, expiresAt)
+
+    if c.db != nil {
+        _, err := c.db.ExecContext(ctx, `
+            INSERT INTO route_cache (cache_key, payload, expires_at)
+            VALUES ($1, $2::jsonb, $3)
+            ON CONFLICT (cache_key) DO UPDATE SET payload = EXCLUDED.payload, expires_at = EXCLUDED.expires_at`,
+            key, string(payload), expiresAt,
+        )
+        if err != nil {
+            log.Printf("Rota önbelleği veritabanına yazılamadı: %v", err)
+        }
+        c.pruneIfDue(ctx, now)
+    }
+}
+
+func (c *RouteCache) storeLocal(key string, payload []byte, expiresAt time.Time) {
+    c.mu.Lock()
+    defer c.mu.Unlock()
+
+    if el, ok := c.entries[key]; ok {
+        entry := el.Value.(*routeCacheEntry)
+        entry.payload, entry.expiresAt = payload, expiresAt
+        c.lru.MoveToFront(el)
+        return
+    }
+
+    c.entries[key] = c.lru.PushFront(&routeCacheEntry{key: key, payload: payload, expiresAt: expiresAt})
+    for c.lru.Len() > c.maxEntries {
+        c.removeLocked(c.lru.Back())
+        c.evictions.Add(1)
+    }
+}
+
+func (c *RouteCache) removeLocked(el *list.Element) {
+    c.lru.Remove(el)
+    delete(c.entries, el.Value.(*routeCacheEntry).key)
+}
+
+// pruneIfDue, süresi dolmuş kalıcı kayıtları saatte bir siler
+func (c *RouteCache) pruneIfDue(ctx context.Context, now time.Time) {
+    c.mu.Lock()
+    if now.Sub(c.lastPrune) < time.Hour {
+        c.mu.Unlock()
+        return
+    }
+    c.lastPrune = now
+    c.mu.Unlock()
+
+    if _, err := c.db.ExecContext(ctx, `DELETE FROM route_cache WHERE expires_at <= $1`, now); err != nil {
+        log.Printf("Süresi dolan rota önbelleği kayıtları silinemedi: %v", err)
+    }
+}
+
+func (c *RouteCache) Stats() RouteCacheStats {
+    if c == nil {
+        return RouteCacheStats{}
+    }
+    c.mu.Lock()
+    entries := c.lru.Len()
+    c.mu.Unlock()
+
+    return RouteCacheStats{
+        Entries:    entries,
+        MaxEntries: c.maxEntries,
+        TTLSeconds: int(c.ttl.Seconds()),
+        Persistent: c.db != nil,
+        Hits:       c.hits.Load(),
+        DBHits:     c.dbHits.Load(),
+        Misses:     c.misses.Load(),
+        Evictions:  c.evictions.Load(),
+    }
+}
@@ -0,0 +1,96 @@
+package services
+
+import (
+    "math"
+    "sort"
+
+    "googlemaps.github.io/maps"
+)
+
+// Koridor sorgusunda izin verilen en geniş yarıçap (km)
+const MaxCorridorRadiusKM = 50
+
+// CorridorStation, rota koridorundaki bir istasyondur.
+// OffsetKM istasyonun rotaya en kısa uzaklığı, DetourKM ise rotadan
+// istasyona gidip dönmenin eklediği yaklaşık mesafedir.
+type CorridorStation struct {
+    Station
+    AlongRouteKM float64 `json:"along_route_km"`
+    OffsetKM     float64 `json:"offset_km"`
+    DetourKM     float64 `json:"detour_km"`
+}
+
+// Koridor sorgusu sonucu: katalog indeksi ve rota üzerindeki konumu
+type corridorHit struct {
+    index    int
+    alongKM  float64
+    offsetKM float64
+}
+
+// alongRoute, rotaya radiusKM içindeki istasyonları bulur. Her segmentin
+// genişletilmiş kutusu ızgarada taranır; böylece uzun rotalarda bile yalnızca
+// rotaya yakın hücreler ziyaret edilir.
+func (idx *spatialIndex) alongRoute(g *routeGeometry, radiusKM float64) []corridorHit {
+    if len(g.points) == 0 || len(idx.stations) == 0 {
+        return nil
+    }
+    if len(g.points) == 1 {
+        p := g.points[0]
+        hits := idx.withinRadius(p.Lat, p.Lng, radiusKM)
+        result := make([]corridorHit, len(hits))
+        for i, hit := range hits {
+            result[i] = corridorHit{index: hit.index, offsetKM: hit.distanceKM}
+        }
+        return result
+    }
+
+    dLat := radiusKM / kmPerDegLat
+    best := make(map[int]corridorHit)
+    for i := 1; i < len(g.points); i++ {
+        a, b := g.points[i-1], g.points[i]
+        minLat, maxLat := math.Min(a.Lat, b.Lat)-dLat, math.Max(a.Lat, b.Lat)+dLat
+        cosLat := math.Cos(math.Min(math.Max(math.Abs(minLat), math.Abs(maxLat)), 89.9) * math.Pi / 180)
+        dLon := math.Min(radiusKM/(kmPerDegLat*cosLat), 180)
+
+        idx.visitBox(minLat, math.Min(a.Lng, b.Lng)-dLon, maxLat, math.Max(a.Lng, b.Lng)+dLon, func(j int) {
+            s := idx.stations[j]
+            along, offset := g.projectSegment(i, s.Latitude, s.Longitude)
+            if offset > radiusKM {
+                return
+            }
+            if prev, ok := best[j]; !ok || offset < prev.offsetKM {
+                best[j] = corridorHit{index: j, alongKM: along, offsetKM: offset}
+            }
+        })
+    }
+
+    hits := make([]corridorHit, 0, len(best))
+    for _, hit := range best {
+        hits = append(hits, hit)
+    }
+    sort.Slice(hits, func(i, j int) bool {
+        if hits[i].alongKM == hits[j].alongKM {
+            return hits[i].index < hits[j].index
+        }
+        return hits[i].alongKM < hits[j].alongKM
+    })
+    return hits
+}
+
+// GetStationsAlongRoute, rota çizgisine radiusKM içindeki istasyonları
+// yolculuk sırasıyla (rota üzerindeki konuma göre) döndürür
+func (s *StationService) GetStationsAlongRoute(points []maps.LatLng, radiusKM float64) []CorridorStation {
+    catalog := s.currentCatalog()
+    hits := catalog.index.alongRoute(newRouteGeometry(points), radiusKM)
+
+    stations := make([]CorridorStation, len(hits))
+    for i, hit := range hits {
+        stations[i] = CorridorStation{
+            Station:      catalog.stations[hit.index],
+            AlongRouteKM: hit.alongKM,
+            OffsetKM:     hit.offsetKM,
+            DetourKM:     2 * hit.offsetKM,
+        }
+    }
+    return stations
+}
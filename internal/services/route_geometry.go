@@ -0,0 +1,72 @@
+package services
+
+import (
+    "charging-stations-backend/internal/utils"
+    "math"
+
+    "googlemaps.github.io/maps"
+)
+
+// routeGeometry, rota çizgisini ve her noktaya kadar olan kümülatif mesafeyi tutar
+type routeGeometry struct {
+    points []maps.LatLng
+    cumKM  []float64
+}
+
+func newRouteGeometry(points []maps.LatLng) *routeGeometry {
+    cum := make([]float64, len(points))
+    for i := 1; i < len(points); i++ {
+        cum[i] = cum[i-1] + utils.CalculateDistance(
+            points[i-1].Lat, points[i-1].Lng, points[i].Lat, points[i].Lng,
+        )
+    }
+    return &routeGeometry{points: points, cumKM: cum}
+}
+
+// lengthKM, çizginin toplam uzunluğudur
+func (g *routeGeometry) lengthKM() float64 {
+    if len(g.cumKM) == 0 {
+        return 0
+    }
+    return g.cumKM[len(g.cumKM)-1]
+}
+
+// project, noktayı çizgiye izdüşürür: başlangıçtan izdüşüme kadar rota
+// üzerindeki mesafeyi ve noktanın çizgiye en kısa uzaklığını (km) döndürür
+func (g *routeGeometry) project(lat, lng float64) (alongKM, offsetKM float64) {
+    if len(g.points) == 0 {
+        return 0, math.Inf(1)
+    }
+    if len(g.points) == 1 {
+        return 0, utils.CalculateDistance(lat, lng, g.points[0].Lat, g.points[0].Lng)
+    }
+
+    offsetKM = math.Inf(1)
+    for i := 1; i < len(g.points); i++ {
+        if along, offset := g.projectSegment(i, lat, lng); offset < offsetKM {
+            alongKM, offsetKM = along, offset
+        }
+    }
+    return alongKM, offsetKM
+}
+
+// projectSegment, noktayı i-1 ile i arasındaki segmente izdüşürür. İzdüşüm
+// kısa segmentlerde yeterince doğru olan yerel eşdikdörtgen düzlemde bulunur,
+// uzaklık ise haversine ile ölçülür.
+func (g *routeGeometry) projectSegment(i int, lat, lng float64) (alongKM, offsetKM float64) {
+    a, b := g.points[i-1], g.points[i]
+    kx := kmPerDegLat * math.Cos(utils.ToRadians((a.Lat+b.Lat)/2))
+
+    // a noktası orijinli düzlem koordinatları (km)
+    bx, by := (b.Lng-a.Lng)*kx, (b.Lat-a.Lat)*kmPerDegLat
+    px, py := (lng-a.Lng)*kx, (lat-a.Lat)*kmPerDegLat
+
+    t := 0.0
+    if seg := bx*bx + by*by; seg > 0 {
+        t = math.Max(0, math.Min(1, (px*bx+py*by)/seg))
+    }
+
+    alongKM = g.cumKM[i-1] + t*(g.cumKM[i]-g.cumKM[i-1])
+    offsetKM = utils.CalculateDistance(lat, lng, a.Lat+t*(b.Lat-a.Lat), a.Lng+t*(b.Lng-a.Lng))
+    return alongKM, offsetKM
+}
@@ -0,0 +1,321 @@
+package services
+
+import (
+    "context"
+    "errors"
+    "fmt"
+    "math"
+
+    "googlemaps.github.io/maps"
+)
+
+// Rota planlama varsayılanları
+const (
+    defaultReserveSoC = 10
+    defaultTargetSoC  = 80
+    defaultCorridorKM = 5
+    // Batarya kapasitesi verilmezse menzilden tahmin edilir
+    defaultConsumptionKWhPerKM = 0.18
+    // Şarj eğrisinin yavaşlaması nedeniyle ortalama güç, azami gücün bu oranıdır
+    chargeEfficiency = 0.8
+    // Planlayıcının bir sonraki durağı aramadan önce ilerlemesi gereken asgari mesafe
+    minStopAdvanceKM = 1
+)
+
+// ErrNoReachableStation, mevcut şarjla erişilebilecek uygun bir istasyon bulunamadığında döner
+var ErrNoReachableStation = errors.New("no compatible charging station within reach")
+
+// ChargePlanRequest, araç bilgileri ve rota uçlarıdır. Yüzdeler 0-100 arasıdır.
+type ChargePlanRequest struct {
+    Origin      maps.LatLng
+    Destination maps.LatLng
+    RangeKM     float64  // tam bataryayla menzil
+    SoCPercent  float64  // başlangıç şarj seviyesi
+    BatteryKWh  float64  // boşsa menzilden tahmin edilir
+    Connectors  []string // aracın desteklediği soket standartları, boşsa hepsi
+    MaxChargeKW float64  // aracın kabul ettiği azami güç, boşsa sınırsız
+    ReserveSoC  float64  // hiçbir noktada altına inilmeyecek seviye
+    TargetSoC   float64  // duraklarda şarj edilecek seviye
+    CorridorKM  float64  // rotadan en fazla bu kadar uzaktaki istasyonlar değerlendirilir
+}
+
+// ChargePlan, rotanın şarj durakları ile bölünmüş bacaklarıdır
+type ChargePlan struct {
+    Provider          string      `json:"provider"`
+    DistanceKM        float64     `json:"distance_km"`
+    DrivingSeconds    int64       `json:"driving_seconds"`
+    ChargingMinutes   float64     `json:"charging_minutes"`
+    Stops             int         `json:"stops"`
+    ArrivalSoCPercent float64     `json:"arrival_soc_percent"`
+    Legs              []ChargeLeg `json:"legs"`
+}
+
+// ChargeLeg, bir duraktan (ya da başlangıçtan) sonrakine giden bacaktır.
+// Station yalnızca bacak bir şarj durağında bitiyorsa doludur.
+type ChargeLeg struct {
+    From                maps.LatLng `json:"from"`
+    To                  maps.LatLng `json:"to"`
+    Station             *Station    `json:"station,omitempty"`
+    DistanceKM          float64     `json:"distance_km"`
+    DurationSeconds     int64       `json:"duration_seconds"`
+    DepartureSoCPercent float64     `json:"departure_soc_percent"`
+    ArrivalSoCPercent   float64     `json:"arrival_soc_percent"`
+    DetourKM            float64     `json:"detour_km,omitempty"`
+    ChargeToSoCPercent  float64     `json:"charge_to_soc_percent,omitempty"`
+    ChargePowerKW       float64     `json:"charge_power_kw,omitempty"`
+    ChargeMinutes       float64     `json:"charge_minutes,omitempty"`
+}
+
+// Rota koridorundaki aday istasyon
+type chargeCandidate struct {
+    station  Station
+    alongKM  float64
+    offsetKM float64
+    powerKW  float64
+}
+
+type RoutePlanner struct {
+    stationService *StationService
+    mapService     *MapService
+}
+
+func NewRoutePlanner(ss *StationService, ms *MapService) *RoutePlanner {
+    return &RoutePlanner{stationService: ss, mapService: ms}
+}
+
+// Validate, eksik alanlara varsayılanları yazar ve tutarsız değerleri reddeder
+func (r *ChargePlanRequest) Validate() error {
+    if r.ReserveSoC == 0 {
+        r.ReserveSoC = defaultReserveSoC
+    }
+    if r.TargetSoC == 0 {
+        r.TargetSoC = defaultTargetSoC
+    }
+    if r.CorridorKM == 0 {
+        r.CorridorKM = defaultCorridorKM
+    }
+
+    switch {
+    case r.RangeKM <= 0:
+        return fmt.Errorf("range_km must be positive")
+    case r.SoCPercent <= 0 || r.SoCPercent > 100:
+        return fmt.Errorf("soc_percent must be between 0 and 100")
+    case r.ReserveSoC < 0 || r.ReserveSoC >= r.TargetSoC:
+        return fmt.Errorf("reserve_soc_percent must be below target_soc_percent")
+    case r.TargetSoC > 100:
+        return fmt.Errorf("target_soc_percent must be at most 100")
+    case r.CorridorKM < 0 || r.CorridorKM > MaxCorridorRadiusKM:
+        return fmt.Errorf("corridor_km must be between 0 and %d", MaxCorridorRadiusKM)
+    case r.BatteryKWh < 0 || r.MaxChargeKW < 0:
+        return fmt.Errorf("battery_kwh and max_charge_kw must not be negative")
+    }
+    return nil
+}
+
+// Plan, rotayı alır ve menzil yetmediği yerlerde koridordaki uyumlu
+// istasyonlardan şarj durakları seçer
+func (p *RoutePlanner) Plan(ctx context.Context, req ChargePlanRequest) (*ChargePlan, error) {
+    if err := req.Validate(); err != nil {
+        return nil, err
+    }
+
+    path, err := p.mapService.GetRoutePath(ctx, req.Origin, req.Destination)
+    if err != nil {
+        return nil, err
+    }
+
+    plan, err := planChargeStops(req, path, p.corridorCandidates(req, path.Points))
+    if err != nil {
+        return nil, err
+    }
+    plan.Provider = path.Provider
+    return plan, nil
+}
+
+// corridorCandidates, koridordaki uyumlu istasyonları rota üzerindeki konumlarıyla döndürür
+func (p *RoutePlanner) corridorCandidates(req ChargePlanRequest, points []maps.LatLng) []chargeCandidate {
+    var candidates []chargeCandidate
+    for _, cs := range p.stationService.GetStationsAlongRoute(points, req.CorridorKM) {
+        power := chargePowerKW(cs.Station, req.Connectors, req.MaxChargeKW)
+        if power <= 0 {
+            continue
+        }
+        candidates = append(candidates, chargeCandidate{
+            station:  cs.Station,
+            alongKM:  cs.AlongRouteKM,
+            offsetKM: cs.OffsetKM,
+            powerKW:  power,
+        })
+    }
+    return candidates
+}
+
+// chargePowerKW, istasyonun araçla uyumlu soketlerindeki en yüksek gücü
+// döndürür; uyumlu soket yoksa 0 döner
+func chargePowerKW(station Station, standards []string, vehicleMaxKW float64) float64 {
+    power := 0.0
+    for _, c := range station.Connectors {
+        if len(standards) > 0 && !containsFold(standards, c.Standard) {
+            continue
+        }
+        kw := c.MaxPowerKW
+        if kw <= 0 {
+            kw = defaultACPowerKW
+            if c.PowerType == "DC" {
+                kw = defaultDCPowerKW
+            }
+        }
+        power = math.Max(power, kw)
+    }
+
+    // Soket listesi çözülemeyen istasyonlar yalnızca standart istenmediğinde kabul edilir
+    if len(station.Connectors) == 0 && len(standards) == 0 {
+        power = defaultACPowerKW
+    }
+    if vehicleMaxKW > 0 {
+        power = math.Min(power, vehicleMaxKW)
+    }
+    return power
+}
+
+// planChargeStops, açgözlü yaklaşımla her adımda erişilebilen en uzak
+// istasyonu seçer. Müsait soketi olan istasyonlar, daha geride kalsalar da
+// tercih edilir; ancak oradan ne hedefe ne de başka bir istasyona
+// ulaşılamıyorsa en uzak istasyona dönülür. Şarj gerektirmeyen (varış seviyesi
+// hedef seviyenin üstünde olan) duraklar seçilmez. Hedefe erişilebilecek kadar
+// şarj yapılınca durulur.
+func planChargeStops(req ChargePlanRequest, path *RoutePath, candidates []chargeCandidate) (*ChargePlan, error) {
+    geomKM := newRouteGeometry(path.Points).lengthKM()
+    totalKM := path.DistanceKM
+    if totalKM <= 0 {
+        totalKM = geomKM
+    }
+    // Çizgi üzerindeki konumları yol mesafesine ölçekle
+    scale := 1.0
+    if geomKM > 0 {
+        scale = totalKM / geomKM
+    }
+
+    secondsPerKM := 60.0 // 60 km/s varsayımı
+    if totalKM > 0 && path.DurationSeconds > 0 {
+        secondsPerKM = float64(path.DurationSeconds) / totalKM
+    }
+    batteryKWh := req.BatteryKWh
+    if batteryKWh <= 0 {
+        batteryKWh = req.RangeKM * defaultConsumptionKWhPerKM
+    }
+    socPerKM := 100 / req.RangeKM
+
+    plan := &ChargePlan{DistanceKM: totalKM}
+    pos, rejoinKM := 0.0, 0.0
+    from, soc := req.Origin, req.SoCPercent
+
+    for {
+        usableKM := (soc - req.ReserveSoC) / socPerKM
+        if remaining := rejoinKM + totalKM - pos; remaining <= usableKM {
+            plan.Legs = append(plan.Legs, ChargeLeg{
+                From:                from,
+                To:                  req.Destination,
+                DistanceKM:          remaining,
+                DurationSeconds:     int64(remaining * secondsPerKM),
+                DepartureSoCPercent: soc,
+                ArrivalSoCPercent:   soc - remaining*socPerKM,
+            })
+            break
+        }
+
+        best, bestAvailable := pickChargeStop(candidates, pos, rejoinKM, usableKM, scale)
+        var next *chargeCandidate
+        var legKM, arrival, chargeTo float64
+        for _, c := range []*chargeCandidate{bestAvailable, best} {
+            if c == nil {
+                continue
+            }
+            along := c.alongKM * scale
+            legKM = rejoinKM + along - pos + c.offsetKM
+            arrival = soc - legKM*socPerKM
+
+            // Hedefe yetecek kadar, en fazla hedef seviyeye kadar şarj et
+            needed := req.ReserveSoC + (c.offsetKM+totalKM-along)*socPerKM
+            chargeTo = math.Max(math.Min(req.TargetSoC, needed), arrival)
+            if chargeTo <= arrival {
+                continue
+            }
+            if c != best && !canContinue(req, candidates, c, chargeTo, totalKM, scale, socPerKM) {
+                continue
+            }
+            next = c
+            break
+        }
+        if next == nil {
+            return nil, fmt.Errorf("%w after %.0f km", ErrNoReachableStation, pos)
+        }
+
+        along := next.alongKM * scale
+        minutes := (chargeTo - arrival) / 100 * batteryKWh / (next.powerKW * chargeEfficiency) * 60
+
+        station := next.station
+        to := maps.LatLng{Lat: station.Latitude, Lng: station.Longitude}
+        plan.Legs = append(plan.Legs, ChargeLeg{
+            From:                from,
+            To:                  to,
+            Station:             &station,
+            DistanceKM:          legKM,
+            DurationSeconds:     int64(legKM * secondsPerKM),
+            DepartureSoCPercent: soc,
+            ArrivalSoCPercent:   arrival,
+            DetourKM:            2 * next.offsetKM,
+            ChargeToSoCPercent:  chargeTo,
+            ChargePowerKW:       next.powerKW,
+            ChargeMinutes:       minutes,
+        })
+
+        plan.Stops++
+        plan.ChargingMinutes += minutes
+        pos, rejoinKM = along, next.offsetKM
+        from, soc = to, chargeTo
+    }
+
+    plan.DistanceKM = 0
+    for _, leg := range plan.Legs {
+        plan.DistanceKM += leg.DistanceKM
+        plan.DrivingSeconds += leg.DurationSeconds
+    }
+    plan.ArrivalSoCPercent = plan.Legs[len(plan.Legs)-1].ArrivalSoCPercent
+    return plan, nil
+}
+
+// canContinue, c durağında chargeTo seviyesine şarj edildikten sonra hedefe ya
+// da daha ileride bir istasyona ulaşılıp ulaşılamayacağını bildirir
+func canContinue(req ChargePlanRequest, candidates []chargeCandidate, c *chargeCandidate, chargeTo, totalKM, scale, socPerKM float64) bool {
+    along := c.alongKM * scale
+    usableKM := (chargeTo - req.ReserveSoC) / socPerKM
+    if c.offsetKM+totalKM-along <= usableKM {
+        return true
+    }
+    best, _ := pickChargeStop(candidates, along, c.offsetKM, usableKM, scale)
+    return best != nil
+}
+
+// pickChargeStop, mevcut konumdan erişilebilen en uzak adayı ve müsait soketi
+// olan en uzak adayı döndürür
+func pickChargeStop(candidates []chargeCandidate, pos, rejoinKM, usableKM, scale float64) (*chargeCandidate, *chargeCandidate) {
+    var best, bestAvailable *chargeCandidate
+    for i := range candidates {
+        c := &candidates[i]
+        along := c.alongKM * scale
+        if along < pos+minStopAdvanceKM || rejoinKM+along-pos+c.offsetKM > usableKM {
+            continue
+        }
+        if best == nil || along > best.alongKM*scale {
+            best = c
+        }
+        // Soket sayısı bilinmeyen istasyonlar müsait kabul edilir
+        if availableSockets(c.station) > 0 || c.station.TotalConnectorsCount == 0 {
+            if bestAvailable == nil || along > bestAvailable.alongKM*scale {
+                bestAvailable = c
+            }
+        }
+    }
+    return best, bestAvailable
+}
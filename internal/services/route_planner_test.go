@@ -0,0 +1,155 @@
+package services
+
+import (
+    "errors"
+    "math"
+    "reflect"
+    "strconv"
+    "testing"
+
+    "googlemaps.github.io/maps"
+)
+
+// straightPath, 39. enlemden kuzeye uzanan yaklaşık totalKM uzunluğunda bir rotadır
+func straightPath(totalKM float64) *RoutePath {
+    degPerKM := 180 / (6371 * math.Pi)
+    return &RoutePath{
+        Points: []maps.LatLng{
+            {Lat: 39, Lng: 32},
+            {Lat: 39 + totalKM*degPerKM, Lng: 32},
+        },
+        DurationSeconds: int64(totalKM * 36),
+    }
+}
+
+// testCandidate, rota üzerinde alongKM'de, rotadan offsetKM uzakta bir adaydır
+func testCandidate(alongKM, offsetKM float64, available bool) chargeCandidate {
+    station := Station{
+        Key:                  "trugo:" + strconv.FormatFloat(alongKM, 'f', -1, 64),
+        TotalConnectorsCount: 2,
+    }
+    if available {
+        station.DCAvailableSocketCount = 1
+    }
+    return chargeCandidate{station: station, alongKM: alongKM, offsetKM: offsetKM, powerKW: 50}
+}
+
+func TestPlanChargeStops(t *testing.T) {
+    tests := []struct {
+        name       string
+        totalKM    float64
+        soc        float64
+        target     float64
+        candidates []chargeCandidate
+        wantStops  []string
+        wantErr    error
+    }{
+        {
+            name:    "destination within range",
+            totalKM: 200,
+            soc:     90,
+        },
+        {
+            name:       "farthest reachable station",
+            totalKM:    380,
+            soc:        80,
+            candidates: []chargeCandidate{testCandidate(100, 0, false), testCandidate(200, 0, false)},
+            wantStops:  []string{"trugo:200"},
+        },
+        {
+            name:       "available station preferred",
+            totalKM:    380,
+            soc:        80,
+            candidates: []chargeCandidate{testCandidate(150, 0, true), testCandidate(200, 0, false)},
+            wantStops:  []string{"trugo:150", "trugo:200"},
+        },
+        {
+            // 10. km'deki müsait istasyondan sapma yüzünden hiçbir yere ulaşılamaz
+            name:    "available station that dead-ends is skipped",
+            totalKM: 500,
+            soc:     80,
+            candidates: []chargeCandidate{
+                testCandidate(10, 20, true),
+                testCandidate(200, 5, false),
+                testCandidate(380, 0, false),
+            },
+            wantStops: []string{"trugo:200", "trugo:380"},
+        },
+        {
+            // Başlangıç seviyesi hedef seviyenin üstünde; 30. km'de şarj gerekmez
+            name:    "stop that needs no charge is skipped",
+            totalKM: 600,
+            soc:     100,
+            candidates: []chargeCandidate{
+                testCandidate(30, 0, true),
+                testCandidate(250, 0, false),
+                testCandidate(450, 0, false),
+            },
+            wantStops: []string{"trugo:250", "trugo:450"},
+        },
+        {
+            name:    "only stops above target soc",
+            totalKM: 600,
+            soc:     100,
+            target:  50,
+            candidates: []chargeCandidate{
+                testCandidate(30, 0, true),
+            },
+            wantErr: ErrNoReachableStation,
+        },
+        {
+            name:       "gap longer than range",
+            totalKM:    700,
+            soc:        80,
+            candidates: []chargeCandidate{testCandidate(200, 0, false), testCandidate(500, 0, false)},
+            wantErr:    ErrNoReachableStation,
+        },
+    }
+
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            req := ChargePlanRequest{RangeKM: 300, SoCPercent: tt.soc, TargetSoC: tt.target}
+            if err := req.Validate(); err != nil {
+                t.Fatal(err)
+            }
+
+            plan, err := planChargeStops(req, straightPath(tt.totalKM), tt.candidates)
+            if tt.wantErr != nil {
+                if !errors.Is(err, tt.wantErr) {
+                    t.Fatalf("err = %v, want %v", err, tt.wantErr)
+                }
+                return
+            }
+            if err != nil {
+                t.Fatal(err)
+            }
+
+            var stops []string
+            for _, leg := range plan.Legs {
+                if leg.ArrivalSoCPercent < req.ReserveSoC-1e-9 {
+                    t.Errorf("leg arrives with %.1f%%, below reserve", leg.ArrivalSoCPercent)
+                }
+                if leg.Station == nil {
+                    continue
+                }
+                stops = append(stops, leg.Station.Key)
+                if leg.ChargeMinutes <= 0 || leg.ChargeToSoCPercent <= leg.ArrivalSoCPercent {
+                    t.Errorf("stop %s charges %.1f%% -> %.1f%% in %.1f min",
+                        leg.Station.Key, leg.ArrivalSoCPercent, leg.ChargeToSoCPercent, leg.ChargeMinutes)
+                }
+                if leg.ChargeToSoCPercent > req.TargetSoC+1e-9 {
+                    t.Errorf("stop %s charges above target to %.1f%%", leg.Station.Key, leg.ChargeToSoCPercent)
+                }
+            }
+            if !reflect.DeepEqual(stops, tt.wantStops) {
+                t.Errorf("stops = %v, want %v", stops, tt.wantStops)
+            }
+            if plan.Stops != len(tt.wantStops) || len(plan.Legs) != len(tt.wantStops)+1 {
+                t.Errorf("plan has %d stops and %d legs", plan.Stops, len(plan.Legs))
+            }
+            if math.Abs(plan.DistanceKM-tt.totalKM) > 2*float64(len(tt.wantStops))*20+1e-6 {
+                t.Errorf("distance = %.1f km, want about %.0f km", plan.DistanceKM, tt.totalKM)
+            }
+        })
+    }
+}
@@ -0,0 +1,187 @@
+package services
+
+import (
+    "fmt"
+    "html"
+    "math"
+    "regexp"
+    "strings"
+
+    "googlemaps.github.io/maps"
+)
+
+// RouteLine, bir rota çizgisidir. Varsayılan olarak nokta listesi döner;
+// Encode sonrası yalnızca Google kodlu polyline metni taşınır.
+type RouteLine struct {
+    Points  []maps.LatLng `json:"points,omitempty"`
+    Encoded string        `json:"encoded,omitempty"`
+}
+
+// RouteStep, tek bir manevra ve ona ait yol parçasıdır
+type RouteStep struct {
+    Instruction     string      `json:"instruction"`
+    HTMLInstruction string      `json:"html_instruction,omitempty"`
+    DistanceMeters  int         `json:"distance_meters"`
+    DurationSeconds int64       `json:"duration_seconds"`
+    Start           maps.LatLng `json:"start"`
+    End             maps.LatLng `json:"end"`
+    Polyline        RouteLine   `json:"polyline"`
+}
+
+type RouteLeg struct {
+    StartAddress    string      `json:"start_address,omitempty"`
+    EndAddress      string      `json:"end_address,omitempty"`
+    Start           maps.LatLng `json:"start"`
+    End             maps.LatLng `json:"end"`
+    DistanceMeters  int         `json:"distance_meters"`
+    DurationSeconds int64       `json:"duration_seconds"`
+    // Yalnızca kalkış saatiyle istenen trafikli tahminlerde doludur
+    DurationInTrafficSeconds int64       `json:"duration_in_traffic_seconds,omitempty"`
+    Steps                    []RouteStep `json:"steps"`
+}
+
+// RouteResult, Directions yanıtının istemciye dönen tam halidir
+type RouteResult struct {
+    // Provider, rotayı hesaplayan yönlendirme sağlayıcısıdır
+    Provider        string `json:"provider"`
+    Summary         string `json:"summary,omitempty"`
+    DistanceMeters  int    `json:"distance_meters"`
+    DurationSeconds int64  `json:"duration_seconds"`
+    // Trafikli süre; sağlayıcı trafik desteklemiyorsa ya da kalkış saati verilmediyse boştur
+    DurationInTrafficSeconds int64             `json:"duration_in_traffic_seconds,omitempty"`
+    Bounds                   maps.LatLngBounds `json:"bounds"`
+    Polyline                 RouteLine         `json:"polyline"`
+    Legs                     []RouteLeg        `json:"legs"`
+    Warnings                 []string          `json:"warnings,omitempty"`
+    // alternatives istendiğinde sağlayıcının önerdiği diğer rotalar
+    Alternatives []*RouteResult `json:"alternatives,omitempty"`
+}
+
+var htmlTag = regexp.MustCompile(`<[^>]*>`)
+
+// plainInstruction, Google'ın HTML talimatını düz metne çevirir.
+// Blok etiketleri (ör. "Destination will be on the right" div'i) boşlukla ayrılır.
+func plainInstruction(s string) string {
+    text := html.UnescapeString(htmlTag.ReplaceAllString(s, " "))
+    return strings.Join(strings.Fields(text), " ")
+}
+
+// newRouteResult, Directions rotasını çözülmüş çizgilerle birlikte dönüştürür
+func newRouteResult(route maps.Route) (*RouteResult, error) {
+    overview, err := route.OverviewPolyline.Decode()
+    if err != nil {
+        return nil, fmt.Errorf("rota çizgisi çözülemedi: %v", err)
+    }
+
+    result := &RouteResult{
+        Summary:  route.Summary,
+        Bounds:   route.Bounds,
+        Polyline: RouteLine{Points: overview},
+        Legs:     make([]RouteLeg, 0, len(route.Legs)),
+        Warnings: route.Warnings,
+    }
+
+    for _, leg := range route.Legs {
+        out := RouteLeg{
+            StartAddress:             leg.StartAddress,
+            EndAddress:               leg.EndAddress,
+            Start:                    leg.StartLocation,
+            End:                      leg.EndLocation,
+            DistanceMeters:           leg.Distance.Meters,
+            DurationSeconds:          int64(leg.Duration.Seconds()),
+            DurationInTrafficSeconds: int64(leg.DurationInTraffic.Seconds()),
+            Steps:                    make([]RouteStep, 0, len(leg.Steps)),
+        }
+        for _, step := range leg.Steps {
+            points, err := step.Polyline.Decode()
+            if err != nil {
+                return nil, fmt.Errorf("adım çizgisi çözülemedi: %v", err)
+            }
+            out.Steps = append(out.Steps, RouteStep{
+                Instruction:     plainInstruction(step.HTMLInstructions),
+                HTMLInstruction: step.HTMLInstructions,
+                DistanceMeters:  step.Distance.Meters,
+                DurationSeconds: int64(step.Duration.Seconds()),
+                Start:           step.StartLocation,
+                End:             step.EndLocation,
+                Polyline:        RouteLine{Points: points},
+            })
+        }
+
+        result.DistanceMeters += out.DistanceMeters
+        result.DurationSeconds += out.DurationSeconds
+        result.DurationInTrafficSeconds += out.DurationInTrafficSeconds
+        result.Legs = append(result.Legs, out)
+    }
+    return result, nil
+}
+
+// Encode, tüm çizgileri kodlu polyline metnine çevirir; yanıt boyutunu ciddi ölçüde küçültür
+func (r *RouteResult) Encode() {
+    for _, alt := range r.Alternatives {
+        alt.Encode()
+    }
+    r.Polyline.encode()
+    for i := range r.Legs {
+        for j := range r.Legs[i].Steps {
+            r.Legs[i].Steps[j].Polyline.encode()
+        }
+    }
+}
+
+func (l *RouteLine) encode() {
+    if l.Encoded == "" {
+        l.Encoded = maps.Encode(l.Points)
+    }
+    l.Points = nil
+}
+
+// boundsOf, noktaları saran kutuyu döndürür
+func boundsOf(points []maps.LatLng) maps.LatLngBounds {
+    if len(points) == 0 {
+        return maps.LatLngBounds{}
+    }
+    b := maps.LatLngBounds{NorthEast: points[0], SouthWest: points[0]}
+    for _, p := range points[1:] {
+        b.NorthEast.Lat = math.Max(b.NorthEast.Lat, p.Lat)
+        b.NorthEast.Lng = math.Max(b.NorthEast.Lng, p.Lng)
+        b.SouthWest.Lat = math.Min(b.SouthWest.Lat, p.Lat)
+        b.SouthWest.Lng = math.Min(b.SouthWest.Lng, p.Lng)
+    }
+    return b
+}
+
+// decodePolyline6, Valhalla'nın kullandığı 1e6 hassasiyetli kodlu polyline'ı çözer.
+// maps.DecodePolyline yalnızca Google'ın 1e5 hassasiyetini destekler.
+func decodePolyline6(encoded string) ([]maps.LatLng, error) {
+    var points []maps.LatLng
+    var lat, lng int64
+    for i := 0; i < len(encoded); {
+        var deltas [2]int64
+        for k := range deltas {
+            var result int64
+            shift := uint(0)
+            for {
+                if i >= len(encoded) {
+                    return nil, fmt.Errorf("polyline beklenmedik şekilde bitti")
+                }
+                b := int64(encoded[i]) - 63
+                i++
+                result |= (b & 0x1f) << shift
+                shift += 5
+                if b < 0x20 {
+                    break
+                }
+            }
+            if result&1 != 0 {
+                deltas[k] = ^(result >> 1)
+            } else {
+                deltas[k] = result >> 1
+            }
+        }
+        lat += deltas[0]
+        lng += deltas[1]
+        points = append(points, maps.LatLng{Lat: float64(lat) / 1e6, Lng: float64(lng) / 1e6})
+    }
+    return points, nil
+}
@@ -0,0 +1,157 @@
+package services
+
+import (
+    "context"
+    "errors"
+    "fmt"
+    "log"
+    "net/http"
+    "os"
+    "strings"
+    "time"
+
+    "googlemaps.github.io/maps"
+)
+
+// Yönlendirme sağlayıcılarına yapılan HTTP isteklerinin zaman aşımı
+const routingRequestTimeout = 10 * time.Second
+
+// Yapılandırma verilmezse kullanılan sağlayıcı zinciri
+const defaultRoutingProviders = "google,haversine"
+
+// Valhalla'ya gönderilen saatlerin varsayılan yerel saat dilimi
+const defaultValhallaTimezone = "Europe/Istanbul"
+
+// RoutingProvider, iki nokta arasında rota ve mesafe hesaplayan bir kaynaktır
+// (Google Directions, OSRM, Valhalla ya da kuş uçuşu tahmin)
+type RoutingProvider interface {
+    Name() string
+    Route(ctx context.Context, origin, destination maps.LatLng, opts TravelOptions) (*RouteResult, error)
+    Distance(ctx context.Context, origin, destination maps.LatLng, opts TravelOptions) (*DistanceResult, error)
+}
+
+// RoutingProvidersFromEnv, ROUTING_PROVIDERS sırasıyla sağlayıcı zincirini
+// kurar (ör. ROUTING_PROVIDERS=osrm,google,haversine). Zincirdeki bir sağlayıcı
+// hata verirse bir sonrakine geçilir. Ücretli Google sonuçları cache'e alınır.
+//
+//	google    GOOGLE_MAPS_API_KEY
+//	osrm      OSRM_URL, isteğe bağlı OSRM_PROFILE (varsayılan driving)
+//	valhalla  VALHALLA_URL, isteğe bağlı VALHALLA_TIMEZONE (varsayılan Europe/Istanbul)
+//	haversine yapılandırma gerektirmez
+func RoutingProvidersFromEnv(cache *RouteCache) []RoutingProvider {
+    names := os.Getenv("ROUTING_PROVIDERS")
+    if names == "" {
+        names = defaultRoutingProviders
+    }
+
+    client := &http.Client{Timeout: routingRequestTimeout}
+    var providers []RoutingProvider
+    for _, name := range strings.Split(names, ",") {
+        name = strings.ToLower(strings.TrimSpace(name))
+        switch name {
+        case "":
+            continue
+        case "google":
+            apiKey := os.Getenv("GOOGLE_MAPS_API_KEY")
+            if apiKey == "" {
+                log.Printf("GOOGLE_MAPS_API_KEY tanımlı değil, google yönlendirme sağlayıcısı atlanıyor")
+                continue
+            }
+            provider, err := NewGoogleRoutingProvider(apiKey, cache)
+            if err != nil {
+                log.Printf("Google Maps istemcisi oluşturulamadı, atlanıyor: %v", err)
+                continue
+            }
+            providers = append(providers, provider)
+        case "osrm":
+            baseURL := os.Getenv("OSRM_URL")
+            if baseURL == "" {
+                log.Printf("OSRM_URL tanımlı değil, osrm yönlendirme sağlayıcısı atlanıyor")
+                continue
+            }
+            providers = append(providers, NewOSRMRoutingProvider(baseURL, os.Getenv("OSRM_PROFILE"), client))
+        case "valhalla":
+            baseURL := os.Getenv("VALHALLA_URL")
+            if baseURL == "" {
+                log.Printf("VALHALLA_URL tanımlı değil, valhalla yönlendirme sağlayıcısı atlanıyor")
+                continue
+            }
+            zoneName := os.Getenv("VALHALLA_TIMEZONE")
+            if zoneName == "" {
+                zoneName = defaultValhallaTimezone
+            }
+            zone, err := time.LoadLocation(zoneName)
+            if err != nil {
+                log.Printf("Geçersiz VALHALLA_TIMEZONE %q, valhalla yönlendirme sağlayıcısı atlanıyor: %v", zoneName, err)
+                continue
+            }
+            providers = append(providers, NewValhallaRoutingProvider(baseURL, client, zone))
+        case "haversine":
+            providers = append(providers, NewHaversineRoutingProvider())
+        default:
+            log.Printf("Bilinmeyen yönlendirme sağlayıcısı %q, atlanıyor", name)
+        }
+    }
+    return providers
+}
+
+// Sağlayıcı hata durumları; yukarı akış kendi durum kodunu vermediğinde kullanılır
+const (
+    RoutingStatusRequestFailed = "REQUEST_FAILED"
+    RoutingStatusEstimateOnly  = "ESTIMATE_ONLY"
+)
+
+// RoutingError, bir sağlayıcının yukarı akış durumuyla birlikte döndürdüğü hatadır
+// (ör. Google'ın ZERO_RESULTS ya da OVER_QUERY_LIMIT durumu)
+type RoutingError struct {
+    Status string
+    Err    error
+}
+
+func (e *RoutingError) Error() string {
+    return fmt.Sprintf("%s: %v", e.Status, e.Err)
+}
+
+func (e *RoutingError) Unwrap() error {
+    return e.Err
+}
+
+// RoutingAttempt, mesafe hesaplanırken başarısız olan bir sağlayıcı denemesidir
+type RoutingAttempt struct {
+    Provider string `json:"provider"`
+    Status   string `json:"status"`
+    Error    string `json:"error"`
+}
+
+func newRoutingAttempt(provider string, err error) RoutingAttempt {
+    attempt := RoutingAttempt{Provider: provider, Status: RoutingStatusRequestFailed, Error: err.Error()}
+    var routingErr *RoutingError
+    if errors.As(err, &routingErr) {
+        attempt.Status = routingErr.Status
+    }
+    return attempt
+}
+
+// NoRoadDistanceError, katı modda hiçbir sağlayıcı yol mesafesi döndüremediğinde döner
+type NoRoadDistanceError struct {
+    Attempts []RoutingAttempt
+}
+
+func (e *NoRoadDistanceError) Error() string {
+    return "no routing provider returned a road distance"
+}
+
+// MatrixRoutingProvider, çok sayıda başlangıç/varış çiftini toplu hesaplayabilen
+// sağlayıcılardır. Hesaplanamayan hücreler nil döner.
+type MatrixRoutingProvider interface {
+    RoutingProvider
+    Matrix(ctx context.Context, origins, destinations []maps.LatLng) ([][]*DistanceResult, error)
+}
+
+func newDistanceGrid(rows, cols int) [][]*DistanceResult {
+    grid := make([][]*DistanceResult, rows)
+    for i := range grid {
+        grid[i] = make([]*DistanceResult, cols)
+    }
+    return grid
+}
@@ -0,0 +1,372 @@
+package services
+
+import (
+    "container/heap"
+    "math"
+    "sort"
+)
+
+const (
+    // Izgara hücre boyu (derece). 0.1° enlemde ~11 km'dir; şehir içi yoğunlukta
+    // hücre başına birkaç düzine istasyon düşer.
+    spatialCellDeg = 0.1
+    kmPerDegLat    = 111.32
+)
+
+type cellKey struct {
+    row int // enlem hücresi
+    col int // boylam hücresi
+}
+
+// spatialIndex, katalog istasyonları için sabit hücreli enlem/boylam ızgarasıdır.
+// Katalogla birlikte oluşturulur ve onun gibi değiştirilemez.
+type spatialIndex struct {
+    stations []Station
+    cells    map[cellKey][]int
+    minRow   int
+    maxRow   int
+    minCol   int
+    maxCol   int
+}
+
+// Sorgu sonucu: katalog indeksi ve noktaya uzaklık
+type spatialHit struct {
+    index      int
+    distanceKM float64
+}
+
+func newSpatialIndex(stations []Station) *spatialIndex {
+    idx := &spatialIndex{
+        stations: stations,
+        cells:    make(map[cellKey][]int),
+        minRow:   math.MaxInt32,
+        maxRow:   math.MinInt32,
+        minCol:   math.MaxInt32,
+        maxCol:   math.MinInt32,
+    }
+
+    for i, station := range stations {
+        key := cellFor(station.Latitude, station.Longitude)
+        idx.cells[key] = append(idx.cells[key], i)
+        idx.minRow = minInt(idx.minRow, key.row)
+        idx.maxRow = maxInt(idx.maxRow, key.row)
+        idx.minCol = minInt(idx.minCol, key.col)
+        idx.maxCol = maxInt(idx.maxCol, key.col)
+    }
+    return idx
+}
+
+func cellFor(lat, lon float64) cellKey {
+    return cellKey{
+        row: int(math.Floor(lat / spatialCellDeg)),
+        col: int(math.Floor(lon / spatialCellDeg)),
+    }
+}
+
+// nearest, noktaya en yakın k istasyonu yakından uzağa döndürür. Merkez
+// hücreden başlayıp halka halka genişler; bir sonraki halkanın en yakın
+// olası uzaklığından daha yakın k sonuç bulunduğunda durur. Halkalarda
+// bakılan hücre sayısı dolu hücre sayısını aşarsa (kutup yakını ya da
+// katalogdan çok uzak noktalar) kalan dolu hücreler doğrudan gezilir; böylece
+// maliyet hiçbir zaman tüm kataloğu bir kez gezmekten fazla olmaz.
+func (idx *spatialIndex) nearest(lat, lon float64, k int) []spatialHit {
+    if k <= 0 || len(idx.stations) == 0 {
+        return nil
+    }
+
+    lat = math.Max(-90, math.Min(90, lat))
+    center := cellFor(lat, lon)
+    maxRing := maxInt(
+        maxInt(absInt(center.row-idx.minRow), absInt(idx.maxRow-center.row)),
+        maxInt(absInt(center.col-idx.minCol), absInt(idx.maxCol-center.col)),
+    )
+
+    var hits []spatialHit
+    add := func(i int) {
+        s := &idx.stations[i]
+        hits = append(hits, spatialHit{index: i, distanceKM: calculateHaversineDistance(lat, lon, s.Latitude, s.Longitude)})
+    }
+
+    lookups := 0
+    complete := true
+    for ring := 0; ring <= maxRing; ring++ {
+        if lookups > len(idx.cells) {
+            idx.visitBeyondRing(center, ring-1, add)
+            break
+        }
+        lookups += idx.visitRing(center, ring, add)
+
+        if len(hits) >= k && countWithin(hits, idx.ringLowerBoundKM(lat, ring)) >= k {
+            complete = ring == maxRing
+            break
+        }
+    }
+
+    hits = selectNearest(hits, k)
+    if !complete {
+        hits = idx.addWrapped(lat, lon, k, hits)
+    }
+    return hits
+}
+
+// addWrapped, halka taraması 180. meridyenin karşısındaki istasyonları
+// ızgarada uzak gördüğünden, k'ncı sonucun yarıçapı meridyeni aşıyorsa karşı
+// taraftaki hücreleri de tarar
+func (idx *spatialIndex) addWrapped(lat, lon float64, k int, hits []spatialHit) []spatialHit {
+    radiusKM := hits[len(hits)-1].distanceKM
+    seen := make(map[int]bool, len(hits))
+    for _, hit := range hits {
+        seen[hit.index] = true
+    }
+
+    added := false
+    idx.visitRadius(lat, lon, radiusKM, true, func(i int) {
+        if seen[i] {
+            return
+        }
+        s := &idx.stations[i]
+        if d := calculateHaversineDistance(lat, lon, s.Latitude, s.Longitude); d <= radiusKM {
+            hits = append(hits, spatialHit{index: i, distanceKM: d})
+            added = true
+        }
+    })
+    if added {
+        hits = selectNearest(hits, k)
+    }
+    return hits
+}
+
+func countWithin(hits []spatialHit, radiusKM float64) int {
+    n := 0
+    for _, hit := range hits {
+        if hit.distanceKM <= radiusKM {
+            n++
+        }
+    }
+    return n
+}
+
+// visitBeyondRing, merkeze Chebyshev uzaklığı ring'den büyük olan dolu hücreleri gezer
+func (idx *spatialIndex) visitBeyondRing(center cellKey, ring int, fn func(int)) {
+    for key, indexes := range idx.cells {
+        if maxInt(absInt(key.row-center.row), absInt(key.col-center.col)) <= ring {
+            continue
+        }
+        for _, i := range indexes {
+            fn(i)
+        }
+    }
+}
+
+// visitRing, merkeze Chebyshev uzaklığı tam ring olan hücrelerdeki istasyonları
+// gezer ve bakılan hücre sayısını döndürür. Yalnızca dolu ızgara sınırları
+// içindeki hücrelere bakılır; böylece katalogdan uzak sorgularda boş halkalar
+// maliyetsiz geçilir.
+func (idx *spatialIndex) visitRing(center cellKey, ring int, fn func(int)) int {
+    lookups := 0
+    visit := func(row, col int) {
+        lookups++
+        for _, i := range idx.cells[cellKey{row: row, col: col}] {
+            fn(i)
+        }
+    }
+
+    if ring == 0 {
+        visit(center.row, center.col)
+        return lookups
+    }
+
+    loCol, hiCol := maxInt(center.col-ring, idx.minCol), minInt(center.col+ring, idx.maxCol)
+    for _, row := range [2]int{center.row - ring, center.row + ring} {
+        if row < idx.minRow || row > idx.maxRow {
+            continue
+        }
+        for col := loCol; col <= hiCol; col++ {
+            visit(row, col)
+        }
+    }
+
+    loRow, hiRow := maxInt(center.row-ring+1, idx.minRow), minInt(center.row+ring-1, idx.maxRow)
+    for _, col := range [2]int{center.col - ring, center.col + ring} {
+        if col < idx.minCol || col > idx.maxCol {
+            continue
+        }
+        for row := loRow; row <= hiRow; row++ {
+            visit(row, col)
+        }
+    }
+    return lookups
+}
+
+// ringLowerBoundKM, ring+1 ve sonraki halkalardaki bir istasyonun noktaya
+// olabilecek en küçük uzaklığıdır. Boylam farkının km karşılığı, istasyonların
+// bulunabileceği enlem bandının (dolu ızgara) ve noktanın kutba en yakın
+// enlemine göre küçültülür. Nokta bandın dışındaysa uzaklık en az bant
+// kenarına olan enlem farkı kadardır; kutup yakınında boylam sınırı sıfıra
+// yaklaşsa da bu sınır geçerli kalır.
+func (idx *spatialIndex) ringLowerBoundKM(lat float64, ring int) float64 {
+    bandMin := float64(idx.minRow) * spatialCellDeg
+    bandMax := float64(idx.maxRow+1) * spatialCellDeg
+    farLat := math.Max(math.Abs(lat), math.Max(math.Abs(bandMin), math.Abs(bandMax)))
+
+    gap := 0.0
+    if lat < bandMin {
+        gap = bandMin - lat
+    } else if lat > bandMax {
+        gap = lat - bandMax
+    }
+
+    // Aynı enlemdeki iki nokta arasındaki büyük daire uzaklığı, paralel
+    // boyunca ölçülenden kısadır; sınır bu yüzden haversine ile hesaplanır
+    span := math.Min(float64(ring)*spatialCellDeg, 180)
+    bound := calculateHaversineDistance(farLat, 0, farLat, span)
+    return math.Max(bound, calculateHaversineDistance(0, 0, gap, 0))
+}
+
+// withinRadius, noktaya radiusKM içindeki istasyonları yakından uzağa döndürür
+func (idx *spatialIndex) withinRadius(lat, lon, radiusKM float64) []spatialHit {
+    var hits []spatialHit
+    collect := func(i int) {
+        s := &idx.stations[i]
+        d := calculateHaversineDistance(lat, lon, s.Latitude, s.Longitude)
+        if d <= radiusKM {
+            hits = append(hits, spatialHit{index: i, distanceKM: d})
+        }
+    }
+
+    idx.visitRadius(lat, lon, radiusKM, false, collect)
+    sortHits(hits)
+    return hits
+}
+
+// visitRadius, noktanın radiusKM çevresini kapsayan kutudaki hücreleri gezer.
+// Kutu 180. meridyeni aşarsa karşı taraftaki hücreler de gezilir. wrappedOnly
+// ise yalnızca karşı taraf gezilir; kutu tüm boylamları kapsıyorsa her durumda
+// bandın tamamı gezilir.
+func (idx *spatialIndex) visitRadius(lat, lon, radiusKM float64, wrappedOnly bool, fn func(int)) {
+    dLat := radiusKM / kmPerDegLat
+    cosLat := math.Cos(math.Min(math.Abs(lat)+dLat, 89.9) * math.Pi / 180)
+    dLon := math.Min(radiusKM/(kmPerDegLat*cosLat), 180)
+    minLat, minLon, maxLat, maxLon := lat-dLat, lon-dLon, lat+dLat, lon+dLon
+
+    if maxLon-minLon >= 360-2*spatialCellDeg {
+        idx.visitBox(minLat, -180, maxLat, 180, fn)
+        return
+    }
+    if !wrappedOnly {
+        idx.visitBox(minLat, math.Max(minLon, -180), maxLat, math.Min(maxLon, 180), fn)
+    }
+    if minLon < -180 {
+        idx.visitBox(minLat, minLon+360, maxLat, 180, fn)
+    }
+    if maxLon > 180 {
+        idx.visitBox(minLat, -180, maxLat, maxLon-360, fn)
+    }
+}
+
+// inBounds, kutu içindeki istasyonların indekslerini döndürür.
+// minLon > maxLon ise kutu 180. meridyeni kesiyor kabul edilir.
+func (idx *spatialIndex) inBounds(minLat, minLon, maxLat, maxLon float64) []int {
+    var result []int
+    collect := func(lo, hi float64) {
+        idx.visitBox(minLat, lo, maxLat, hi, func(i int) {
+            s := &idx.stations[i]
+            if s.Latitude >= minLat && s.Latitude <= maxLat && s.Longitude >= lo && s.Longitude <= hi {
+                result = append(result, i)
+            }
+        })
+    }
+
+    if minLon > maxLon {
+        collect(minLon, 180)
+        collect(-180, maxLon)
+    } else {
+        collect(minLon, maxLon)
+    }
+    sort.Ints(result)
+    return result
+}
+
+// visitBox, kutuyla kesişen (ve dolu ızgara sınırları içindeki) hücreleri gezer
+func (idx *spatialIndex) visitBox(minLat, minLon, maxLat, maxLon float64, fn func(int)) {
+    lo := cellFor(minLat, minLon)
+    hi := cellFor(maxLat, maxLon)
+    lo.row, hi.row = maxInt(lo.row, idx.minRow), minInt(hi.row, idx.maxRow)
+    lo.col, hi.col = maxInt(lo.col, idx.minCol), minInt(hi.col, idx.maxCol)
+
+    for row := lo.row; row <= hi.row; row++ {
+        for col := lo.col; col <= hi.col; col++ {
+            for _, i := range idx.cells[cellKey{row: row, col: col}] {
+                fn(i)
+            }
+        }
+    }
+}
+
+func sortHits(hits []spatialHit) {
+    sort.Slice(hits, func(i, j int) bool {
+        return hitLess(hits[i], hits[j])
+    })
+}
+
+func hitLess(a, b spatialHit) bool {
+    if a.distanceKM == b.distanceKM {
+        return a.index < b.index
+    }
+    return a.distanceKM < b.distanceKM
+}
+
+// selectNearest, sonuçların en yakın k tanesini sıralı döndürür. Tüm listeyi
+// sıralamak yerine k elemanlı bir yığın kullanır.
+func selectNearest(hits []spatialHit, k int) []spatialHit {
+    if len(hits) <= k {
+        sortHits(hits)
+        return hits
+    }
+
+    h := farthestFirst(append([]spatialHit(nil), hits[:k]...))
+    heap.Init(&h)
+    for _, hit := range hits[k:] {
+        if hitLess(hit, h[0]) {
+            h[0] = hit
+            heap.Fix(&h, 0)
+        }
+    }
+    sortHits(h)
+    return h
+}
+
+// farthestFirst, kökünde en uzak sonucun durduğu yığındır
+type farthestFirst []spatialHit
+
+func (h farthestFirst) Len() int            { return len(h) }
+func (h farthestFirst) Less(i, j int) bool  { return hitLess(h[j], h[i]) }
+func (h farthestFirst) Swap(i, j int)       { h[i], h[j] = h[j], h[i] }
+func (h *farthestFirst) Push(x interface{}) { *h = append(*h, x.(spatialHit)) }
+func (h *farthestFirst) Pop() interface{} {
+    old := *h
+    x := old[len(old)-1]
+    *h = old[:len(old)-1]
+    return x
+}
+
+func minInt(a, b int) int {
+    if a < b {
+        return a
+    }
+    return b
+}
+
+func maxInt(a, b int) int {
+    if a > b {
+        return a
+    }
+    return b
+}
+
+func absInt(a int) int {
+    if a < 0 {
+        return -a
+    }
+    return a
+}
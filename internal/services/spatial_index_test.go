@@ -0,0 +1,147 @@
+package services
+
+import (
+    "fmt"
+    "math/rand"
+    "sort"
+    "testing"
+)
+
+// linearScanNearest, indeks öncesindeki doğrusal taramadır; yalnızca testlerde
+// karşılaştırma için tutulur
+func linearScanNearest(stations []Station, lat, lon float64, k int) []spatialHit {
+    hits := make([]spatialHit, len(stations))
+    for i, s := range stations {
+        hits[i] = spatialHit{index: i, distanceKM: calculateHaversineDistance(lat, lon, s.Latitude, s.Longitude)}
+    }
+    sortHits(hits)
+    if len(hits) > k {
+        hits = hits[:k]
+    }
+    return hits
+}
+
+// testStations, çoğu Türkiye sınırları içinde, bir kısmı dünyaya dağılmış istasyonlar üretir
+func testStations(n int, seed int64) []Station {
+    rng := rand.New(rand.NewSource(seed))
+    stations := make([]Station, n)
+    for i := range stations {
+        lat, lon := 36+rng.Float64()*6, 26+rng.Float64()*19
+        if i%50 == 0 {
+            lat, lon = -60+rng.Float64()*130, -179+rng.Float64()*358
+        }
+        stations[i] = Station{Key: fmt.Sprintf("trugo:%d", i), Latitude: lat, Longitude: lon}
+    }
+    return stations
+}
+
+func TestNearestMatchesLinearScan(t *testing.T) {
+    stations := testStations(10000, 1)
+    idx := newSpatialIndex(stations)
+
+    queries := [][2]float64{
+        {41.0082, 28.9784}, // İstanbul
+        {39.9334, 32.8597}, // Ankara
+        {36.0, 45.0},       // katalog köşesi
+        {89.99, 10},        // kuzey kutbu
+        {-89.99, -120},     // güney kutbu
+        {-45, 170},         // katalogdan uzak
+        {0, 0},
+    }
+    rng := rand.New(rand.NewSource(2))
+    for i := 0; i < 30; i++ {
+        queries = append(queries, [2]float64{-90 + rng.Float64()*180, -180 + rng.Float64()*360})
+    }
+
+    for _, q := range queries {
+        for _, k := range []int{1, 10, 100} {
+            got := idx.nearest(q[0], q[1], k)
+            want := linearScanNearest(stations, q[0], q[1], k)
+            if len(got) != len(want) {
+                t.Fatalf("nearest(%v, %d) returned %d hits, want %d", q, k, len(got), len(want))
+            }
+            for i := range want {
+                if got[i] != want[i] {
+                    t.Fatalf("nearest(%v, %d)[%d] = %+v, want %+v", q, k, i, got[i], want[i])
+                }
+            }
+        }
+    }
+}
+
+func TestWithinRadiusMatchesLinearScan(t *testing.T) {
+    stations := testStations(5000, 3)
+    idx := newSpatialIndex(stations)
+
+    tests := []struct {
+        lat, lon float64
+        radius   float64
+    }{
+        {39.9334, 32.8597, 1},
+        {39.9334, 32.8597, 10},
+        {39.9334, 32.8597, 50},
+        {39.9334, 32.8597, 250},
+        {-20, 179.9, 2000}, // 180. meridyeni aşan çember
+        {60, -179.9, 3000},
+    }
+    for _, tt := range tests {
+        var want []spatialHit
+        for _, hit := range linearScanNearest(stations, tt.lat, tt.lon, len(stations)) {
+            if hit.distanceKM <= tt.radius {
+                want = append(want, hit)
+            }
+        }
+
+        got := idx.withinRadius(tt.lat, tt.lon, tt.radius)
+        if len(got) != len(want) {
+            t.Fatalf("withinRadius(%v) returned %d hits, want %d", tt, len(got), len(want))
+        }
+        for i := range want {
+            if got[i] != want[i] {
+                t.Fatalf("withinRadius(%v)[%d] = %+v, want %+v", tt, i, got[i], want[i])
+            }
+        }
+    }
+}
+
+func TestInBoundsAcrossAntimeridian(t *testing.T) {
+    stations := []Station{
+        {Key: "a", Latitude: 10, Longitude: 179.5},
+        {Key: "b", Latitude: 10, Longitude: -179.5},
+        {Key: "c", Latitude: 10, Longitude: 0},
+    }
+    got := newSpatialIndex(stations).inBounds(5, 179, 15, -179)
+    if !sort.IntsAreSorted(got) || len(got) != 2 || got[0] != 0 || got[1] != 1 {
+        t.Fatalf("inBounds = %v, want [0 1]", got)
+    }
+}
+
+var benchmarkSizes = []int{10000, 50000, 100000}
+
+func BenchmarkNearest(b *testing.B) {
+    for _, n := range benchmarkSizes {
+        stations := testStations(n, 1)
+        idx := newSpatialIndex(stations)
+        b.Run(fmt.Sprintf("stations=%d", n), func(b *testing.B) {
+            for i := 0; i < b.N; i++ {
+                idx.nearest(39.9334, 32.8597, 10)
+            }
+        })
+        b.Run(fmt.Sprintf("stations=%d/pole", n), func(b *testing.B) {
+            for i := 0; i < b.N; i++ {
+                idx.nearest(89.9, 32.8597, 10)
+            }
+        })
+    }
+}
+
+func BenchmarkLinearScan(b *testing.B) {
+    for _, n := range benchmarkSizes {
+        stations := testStations(n, 1)
+        b.Run(fmt.Sprintf("stations=%d", n), func(b *testing.B) {
+            for i := 0; i < b.N; i++ {
+                linearScanNearest(stations, 39.9334, 32.8597, 10)
+            }
+        })
+    }
+}
@@ -0,0 +1,74 @@
+package services
+
+import (
+    "reflect"
+    "time"
+)
+
+// stationCatalog, bir yenilemede üretilen değiştirilemez istasyon kümesidir.
+// Yayınlandıktan sonra hiçbir alanı değiştirilmez; yenileme yeni bir katalog
+// oluşturup atomik olarak yerine koyar, okuyucular yarım yazılmış veri görmez.
+type stationCatalog struct {
+    stations []Station
+    byKey    map[string]int // istasyon anahtarı -> stations indeksi
+    index    *spatialIndex
+    loadedAt time.Time
+}
+
+func newStationCatalog(stations []Station, loadedAt time.Time) *stationCatalog {
+    byKey := make(map[string]int, len(stations))
+    for i, station := range stations {
+        byKey[station.Key] = i
+    }
+
+    return &stationCatalog{
+        stations: stations,
+        byKey:    byKey,
+        index:    newSpatialIndex(stations),
+        loadedAt: loadedAt,
+    }
+}
+
+// stampChangeTimes, istasyonlara verilerinin en son değiştiği zamanı yazar.
+// Önceki katalogda aynı veriyle bulunan istasyonlar eski zamanlarını korur;
+// böylece kaynağı zaman bilgisi taşımayan istasyonlar her yenilemede
+// değişmiş görünmez.
+func stampChangeTimes(prev *stationCatalog, stations []Station, at time.Time) {
+    for i := range stations {
+        stations[i].ChangedAt = at
+        if prev == nil {
+            continue
+        }
+        j, ok := prev.byKey[stations[i].Key]
+        if !ok {
+            continue
+        }
+
+        old := prev.stations[j]
+        changedAt := old.ChangedAt
+        old.ChangedAt = at
+        if reflect.DeepEqual(old, stations[i]) {
+            stations[i].ChangedAt = changedAt
+        }
+    }
+}
+
+// find, anahtara göre istasyonun bir kopyasını döndürür
+func (c *stationCatalog) find(key string) *Station {
+    i, ok := c.byKey[key]
+    if !ok {
+        return nil
+    }
+    stationCopy := c.stations[i]
+    return &stationCopy
+}
+
+// fromHits, indeks sonuçlarını mesafeleri yazılmış istasyon kopyalarına çevirir
+func (c *stationCatalog) fromHits(hits []spatialHit) []Station {
+    stations := make([]Station, len(hits))
+    for i, hit := range hits {
+        stations[i] = c.stations[hit.index]
+        stations[i].DistanceKM = hit.distanceKM
+    }
+    return stations
+}
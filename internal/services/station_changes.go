@@ -0,0 +1,110 @@
+package services
+
+import (
+    "charging-stations-backend/internal/models"
+    "reflect"
+    "time"
+)
+
+// Değişiklik tipleri
+const (
+    ChangeAdded             = "added"
+    ChangeRemoved           = "removed"
+    ChangeRelocated         = "relocated"
+    ChangeConnectorsChanged = "connectors_changed"
+    ChangeWentOffline       = "went_offline"
+    ChangeCameOnline        = "came_online"
+)
+
+// Bu mesafenin altındaki koordinat oynamaları taşınma sayılmaz (metre)
+const relocationThresholdMeters = 50
+
+// StationChange, iki katalog arasında bir istasyonda tespit edilen değişikliktir
+type StationChange struct {
+    ID                 int64              `json:"id,omitempty"`
+    Type               string             `json:"type"`
+    StationKey         string             `json:"station_id"`
+    Name               string             `json:"name"`
+    Provider           string             `json:"provider"`
+    Latitude           float64            `json:"latitude"`
+    Longitude          float64            `json:"longitude"`
+    PreviousLatitude   *float64           `json:"previous_latitude,omitempty"`
+    PreviousLongitude  *float64           `json:"previous_longitude,omitempty"`
+    MovedMeters        float64            `json:"moved_meters,omitempty"`
+    ConnectorList      string             `json:"connector_list,omitempty"`
+    PreviousConnectors string             `json:"previous_connector_list,omitempty"`
+    Connectors         []models.Connector `json:"connectors,omitempty"`
+    DetectedAt         time.Time          `json:"detected_at"`
+}
+
+// EventType, değişikliğin olay merkezinde yayınlandığı tiptir (ör. "station_added")
+func (c StationChange) EventType() string {
+    return "station_" + c.Type
+}
+
+func newStationChange(changeType string, station Station, at time.Time) StationChange {
+    return StationChange{
+        Type:          changeType,
+        StationKey:    station.Key,
+        Name:          station.Name,
+        Provider:      station.Provider,
+        Latitude:      station.Latitude,
+        Longitude:     station.Longitude,
+        ConnectorList: station.ConnectorList,
+        Connectors:    station.Connectors,
+        DetectedAt:    at,
+    }
+}
+
+// isOffline, istasyonun tüm soketleri arızalı ya da kullanım dışıysa doğrudur
+func isOffline(s Station) bool {
+    return s.TotalConnectorsCount > 0 && s.ErrorDeviceCount+s.UnavailableDeviceCount >= s.TotalConnectorsCount
+}
+
+// diffCatalogs, önceki kataloğa göre eklenen, kaldırılan, taşınan, soketleri
+// değişen ve çevrimdışı/çevrimiçi olan istasyonları bulur. İlk yüklemede
+// (prev == nil) karşılaştırılacak bir şey olmadığından değişiklik üretmez.
+func diffCatalogs(prev, next *stationCatalog, at time.Time) []StationChange {
+    if prev == nil {
+        return nil
+    }
+
+    var changes []StationChange
+    for _, station := range next.stations {
+        i, ok := prev.byKey[station.Key]
+        if !ok {
+            changes = append(changes, newStationChange(ChangeAdded, station, at))
+            continue
+        }
+        old := prev.stations[i]
+
+        moved := calculateHaversineDistance(old.Latitude, old.Longitude, station.Latitude, station.Longitude) * 1000
+        if moved >= relocationThresholdMeters {
+            change := newStationChange(ChangeRelocated, station, at)
+            change.PreviousLatitude = &old.Latitude
+            change.PreviousLongitude = &old.Longitude
+            change.MovedMeters = moved
+            changes = append(changes, change)
+        }
+
+        if old.ConnectorList != station.ConnectorList || !reflect.DeepEqual(old.Connectors, station.Connectors) {
+            change := newStationChange(ChangeConnectorsChanged, station, at)
+            change.PreviousConnectors = old.ConnectorList
+            changes = append(changes, change)
+        }
+
+        switch wasOffline, offline := isOffline(old), isOffline(station); {
+        case !wasOffline && offline:
+            changes = append(changes, newStationChange(ChangeWentOffline, station, at))
+        case wasOffline && !offline:
+            changes = append(changes, newStationChange(ChangeCameOnline, station, at))
+        }
+    }
+
+    for _, station := range prev.stations {
+        if _, ok := next.byKey[station.Key]; !ok {
+            changes = append(changes, newStationChange(ChangeRemoved, station, at))
+        }
+    }
+    return changes
+}
@@ -0,0 +1,110 @@
+package services
+
+import (
+    "math"
+    "sort"
+)
+
+const (
+    // Bu zoom ve üstünde istasyonlar kümelenmeden döner
+    ClusterMaxZoom = 14
+    // Bir harita karosunun (256px) kaç hücreye bölüneceği; ~64px'lik kümeler verir
+    clusterCellsPerTile = 4
+)
+
+// Düşük zoom'da aynı ızgara hücresine düşen istasyonların özeti
+type StationCluster struct {
+    Latitude               float64    `json:"latitude"`
+    Longitude              float64    `json:"longitude"`
+    Count                  int        `json:"count"`
+    ACAvailableSocketCount int        `json:"ac_available_sockets_count"`
+    DCAvailableSocketCount int        `json:"dc_available_sockets_count"`
+    StationColor           string     `json:"station_color"`
+    Bounds                 [4]float64 `json:"bbox"` // minLng, minLat, maxLng, maxLat
+}
+
+// ClusterStations, istasyonları zoom'a göre boyutlanan sabit bir ızgarada
+// kümeler. Izgara dünyaya sabitlendiği için harita kaydırıldığında kümeler
+// yer değiştirmez. Tek istasyonlu hücreler küme yerine istasyon olarak döner.
+func ClusterStations(stations []Station, zoom int) ([]StationCluster, []Station) {
+    if zoom >= ClusterMaxZoom {
+        return []StationCluster{}, stations
+    }
+    if zoom < 0 {
+        zoom = 0
+    }
+
+    cellDeg := 360 / math.Pow(2, float64(zoom)) / clusterCellsPerTile
+
+    type bucket struct {
+        members []int
+        order   int
+    }
+    buckets := make(map[cellKey]*bucket)
+    for i, station := range stations {
+        key := cellKey{
+            row: int(math.Floor(station.Latitude / cellDeg)),
+            col: int(math.Floor(station.Longitude / cellDeg)),
+        }
+        b, ok := buckets[key]
+        if !ok {
+            b = &bucket{order: len(buckets)}
+            buckets[key] = b
+        }
+        b.members = append(b.members, i)
+    }
+
+    ordered := make([]*bucket, 0, len(buckets))
+    for _, b := range buckets {
+        ordered = append(ordered, b)
+    }
+    sort.Slice(ordered, func(i, j int) bool {
+        return ordered[i].order < ordered[j].order
+    })
+
+    clusters := []StationCluster{}
+    singles := []Station{}
+    for _, b := range ordered {
+        if len(b.members) == 1 {
+            singles = append(singles, stations[b.members[0]])
+            continue
+        }
+        clusters = append(clusters, newStationCluster(stations, b.members))
+    }
+    return clusters, singles
+}
+
+func newStationCluster(stations []Station, members []int) StationCluster {
+    first := stations[members[0]]
+    cluster := StationCluster{
+        Count:  len(members),
+        Bounds: [4]float64{first.Longitude, first.Latitude, first.Longitude, first.Latitude},
+    }
+
+    colors := make(map[string]int)
+    for _, i := range members {
+        s := stations[i]
+        cluster.Latitude += s.Latitude
+        cluster.Longitude += s.Longitude
+        cluster.ACAvailableSocketCount += s.ACAvailableSocketCount
+        cluster.DCAvailableSocketCount += s.DCAvailableSocketCount
+        cluster.Bounds[0] = math.Min(cluster.Bounds[0], s.Longitude)
+        cluster.Bounds[1] = math.Min(cluster.Bounds[1], s.Latitude)
+        cluster.Bounds[2] = math.Max(cluster.Bounds[2], s.Longitude)
+        cluster.Bounds[3] = math.Max(cluster.Bounds[3], s.Latitude)
+        if s.StationColor != "" {
+            colors[s.StationColor]++
+        }
+    }
+    cluster.Latitude /= float64(len(members))
+    cluster.Longitude /= float64(len(members))
+
+    // En sık renk; eşitlikte alfabetik ilk renk, sonuç kararlı olsun
+    for color, n := range colors {
+        best := colors[cluster.StationColor]
+        if n > best || (n == best && color < cluster.StationColor) {
+            cluster.StationColor = color
+        }
+    }
+    return cluster
+}
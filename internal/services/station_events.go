@@ -0,0 +1,136 @@
+package services
+
+import (
+    "charging-stations-backend/internal/models"
+    "sync"
+    "sync/atomic"
+    "time"
+)
+
+// İstasyon olay tipleri
+const (
+    EventAvailabilityChanged = "availability_changed"
+    EventReviewCreated       = "review_created"
+    EventRatingChanged       = "rating_changed"
+)
+
+// Bir istasyonun soket durum sayaçları
+type Availability struct {
+    ACAvailableSocketCount int `json:"ac_available_sockets_count"`
+    DCAvailableSocketCount int `json:"dc_available_sockets_count"`
+    ErrorDeviceCount       int `json:"error_device_count"`
+    UnavailableDeviceCount int `json:"unavailable_device_count"`
+}
+
+func availabilityOf(s Station) Availability {
+    return Availability{
+        ACAvailableSocketCount: s.ACAvailableSocketCount,
+        DCAvailableSocketCount: s.DCAvailableSocketCount,
+        ErrorDeviceCount:       s.ErrorDeviceCount,
+        UnavailableDeviceCount: s.UnavailableDeviceCount,
+    }
+}
+
+// StationEvent, abonelere yayınlanan bir istasyon değişikliğidir
+type StationEvent struct {
+    Type       string               `json:"type"`
+    StationKey string               `json:"station_key"`
+    Station    *Station             `json:"station,omitempty"`
+    Previous   *Availability        `json:"previous,omitempty"`
+    Current    *Availability        `json:"current,omitempty"`
+    Review     *models.Review       `json:"review,omitempty"`
+    Stats      *models.StationStats `json:"stats,omitempty"`
+    Change     *StationChange       `json:"change,omitempty"`
+    Time       time.Time            `json:"time"`
+}
+
+// EventHub, olayları tüm abonelere dağıtır. Yayın asla bloklamaz: tamponu
+// dolu olan abonenin olayı düşürülür ve Dropped sayacı artırılır.
+type EventHub struct {
+    mu   sync.RWMutex
+    subs map[*Subscription]struct{}
+}
+
+type Subscription struct {
+    C       <-chan StationEvent
+    ch      chan StationEvent
+    hub     *EventHub
+    dropped atomic.Int64
+    once    sync.Once
+}
+
+func NewEventHub() *EventHub {
+    return &EventHub{subs: make(map[*Subscription]struct{})}
+}
+
+// Subscribe, verilen tampon boyutuyla yeni bir abonelik açar
+func (h *EventHub) Subscribe(buffer int) *Subscription {
+    ch := make(chan StationEvent, buffer)
+    sub := &Subscription{C: ch, ch: ch, hub: h}
+
+    h.mu.Lock()
+    h.subs[sub] = struct{}{}
+    h.mu.Unlock()
+    return sub
+}
+
+func (h *EventHub) Publish(events ...StationEvent) {
+    h.mu.RLock()
+    defer h.mu.RUnlock()
+
+    for sub := range h.subs {
+        for _, event := range events {
+            select {
+            case sub.ch <- event:
+            default:
+                sub.dropped.Add(1)
+            }
+        }
+    }
+}
+
+// Dropped, tampon dolduğu için düşürülen olay sayısıdır
+func (s *Subscription) Dropped() int64 {
+    return s.dropped.Load()
+}
+
+// Close, aboneliği kapatır; C kanalı kapanır
+func (s *Subscription) Close() {
+    s.once.Do(func() {
+        s.hub.mu.Lock()
+        delete(s.hub.subs, s)
+        s.hub.mu.Unlock()
+        close(s.ch)
+    })
+}
+
+// diffAvailability, iki katalog arasında soket sayaçları değişen istasyonlar
+// için olay üretir. Yeni eklenen ya da kaldırılan istasyonlar burada sayılmaz.
+func diffAvailability(prev, next *stationCatalog, at time.Time) []StationEvent {
+    if prev == nil {
+        return nil
+    }
+
+    var events []StationEvent
+    for _, station := range next.stations {
+        i, ok := prev.byKey[station.Key]
+        if !ok {
+            continue
+        }
+        before, after := availabilityOf(prev.stations[i]), availabilityOf(station)
+        if before == after {
+            continue
+        }
+
+        stationCopy := station
+        events = append(events, StationEvent{
+            Type:       EventAvailabilityChanged,
+            StationKey: station.Key,
+            Station:    &stationCopy,
+            Previous:   &before,
+            Current:    &after,
+            Time:       at,
+        })
+    }
+    return events
+}
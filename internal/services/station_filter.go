@@ -0,0 +1,141 @@
+package services
+
+import (
+    "strings"
+)
+
+// StationFilter, istasyon listesine uygulanan filtrelerdir. Boş alanlar filtre uygulamaz.
+type StationFilter struct {
+    Brands      []string
+    Connectors  []string // soket standartları (CCS2, Type2, ...)
+    MinPowerKW  float64
+    ACAvailable bool
+    DCAvailable bool
+    Colors      []string
+    MinRating   float64
+    Query       string // isimde serbest metin arama
+}
+
+// Filtre çiplerinde gösterilecek sayılar. Her boyutun sayıları, o boyut
+// hariç diğer tüm filtreler uygulanarak hesaplanır; böylece seçili bir
+// markanın yanındaki diğer markalar da sayılarıyla görünmeye devam eder.
+type StationFacets struct {
+    Brands      map[string]int `json:"brands"`
+    Connectors  map[string]int `json:"connectors"`
+    Colors      map[string]int `json:"colors"`
+    ACAvailable int            `json:"ac_available"`
+    DCAvailable int            `json:"dc_available"`
+}
+
+// Bir istasyonun her filtre boyutuna uyup uymadığı
+type filterMatch struct {
+    base      bool // puan ve metin arama, tüm facet'lere uygulanır
+    brand     bool
+    connector bool
+    color     bool
+    ac        bool
+    dc        bool
+}
+
+func (m filterMatch) all() bool {
+    return m.base && m.brand && m.connector && m.color && m.ac && m.dc
+}
+
+func (f StationFilter) match(station Station) filterMatch {
+    return filterMatch{
+        base: station.AverageRating >= f.MinRating &&
+            (f.Query == "" || strings.Contains(foldText(station.Name), foldText(f.Query))),
+        brand:     len(f.Brands) == 0 || containsFold(f.Brands, station.Brand),
+        connector: f.matchConnector(station, f.Connectors),
+        color:     len(f.Colors) == 0 || containsFold(f.Colors, station.StationColor),
+        ac:        !f.ACAvailable || station.ACAvailableSocketCount > 0,
+        dc:        !f.DCAvailable || station.DCAvailableSocketCount > 0,
+    }
+}
+
+// matchConnector, seçili standartlardan en az MinPowerKW gücünde bir soket arar
+func (f StationFilter) matchConnector(station Station, standards []string) bool {
+    if len(standards) == 0 && f.MinPowerKW <= 0 {
+        return true
+    }
+    for _, c := range station.Connectors {
+        if (len(standards) == 0 || containsFold(standards, c.Standard)) && c.MaxPowerKW >= f.MinPowerKW {
+            return true
+        }
+    }
+    return false
+}
+
+// FilterStations, filtreye uyan istasyonları sırasını koruyarak döndürür
+func FilterStations(stations []Station, f StationFilter) []Station {
+    result := make([]Station, 0, len(stations))
+    for _, station := range stations {
+        if f.match(station).all() {
+            result = append(result, station)
+        }
+    }
+    return result
+}
+
+// ComputeFacets, filtre çipleri için boyut bazında sayıları hesaplar
+func ComputeFacets(stations []Station, f StationFilter) StationFacets {
+    facets := StationFacets{
+        Brands:     make(map[string]int),
+        Connectors: make(map[string]int),
+        Colors:     make(map[string]int),
+    }
+
+    for _, station := range stations {
+        m := f.match(station)
+        if !m.base {
+            continue
+        }
+
+        if m.connector && m.color && m.ac && m.dc && station.Brand != "" {
+            facets.Brands[station.Brand]++
+        }
+        if m.brand && m.connector && m.ac && m.dc && station.StationColor != "" {
+            facets.Colors[station.StationColor]++
+        }
+        if m.brand && m.color && m.ac && m.dc {
+            seen := make(map[string]bool)
+            for _, c := range station.Connectors {
+                if c.MaxPowerKW >= f.MinPowerKW && !seen[c.Standard] {
+                    seen[c.Standard] = true
+                    facets.Connectors[c.Standard]++
+                }
+            }
+        }
+        if m.brand && m.connector && m.color && m.dc && station.ACAvailableSocketCount > 0 {
+            facets.ACAvailable++
+        }
+        if m.brand && m.connector && m.color && m.ac && station.DCAvailableSocketCount > 0 {
+            facets.DCAvailable++
+        }
+    }
+    return facets
+}
+
+func containsFold(values []string, s string) bool {
+    for _, v := range values {
+        if strings.EqualFold(v, s) {
+            return true
+        }
+    }
+    return false
+}
+
+// Türkçe karakterleri ASCII karşılıklarına indirger; "İstanbul", "istanbul"
+// ve "ISTANBUL" aynı şekilde aranabilir
+var turkishFolder = strings.NewReplacer(
+    "İ", "i", "I", "i", "ı", "i",
+    "Ş", "s", "ş", "s",
+    "Ğ", "g", "ğ", "g",
+    "Ü", "u", "ü", "u",
+    "Ö", "o", "ö", "o",
+    "Ç", "c", "ç", "c",
+)
+
+func foldText(s string) string {
+    return strings.ToLower(turkishFolder.Replace(s))
+}
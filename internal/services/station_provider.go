@@ -0,0 +1,13 @@
+package services
+
+import (
+    "context"
+)
+
+// StationProvider, istasyon verisi sağlayan bir CPO kaynağıdır (Trugo, OCPI, ...).
+// Name, katalogda istasyon anahtarlarının ön eki olarak kullanılır ve
+// servis içinde benzersiz olmalıdır.
+type StationProvider interface {
+    Name() string
+    FetchStations(ctx context.Context) ([]Station, error)
+}
@@ -1,151 +1,412 @@
 package services
 
 import (
-    "encoding/json"
+    "charging-stations-backend/internal/models"
+    "context"
     "fmt"
-    "io/ioutil"
     "log"
-    "net/http"
-    "sort"
+    "os"
     "math"
     "strconv"
+    "strings"
+    "sync"
+    "sync/atomic"
+    "time"
 )
 
-// API yanıt yapısı
-type TrugoResponse struct {
-    Status   string         `json:"status"`
-    Message  string         `json:"message"`
-    Data     StationsData   `json:"data"`
-    Options  interface{}    `json:"options"`
-}
-
-// Stations verisi için ara yapı
-type StationsData struct {
-    Stations []Station `json:"stations"`
-}
+// Varsayılan önbellek süresi, STATION_CACHE_TTL ile değiştirilebilir
+const defaultStationCacheTTL = 5 * time.Minute
 
 // İstasyon modeli
 type Station struct {
+    // Key, sağlayıcıya göre ad alanlı benzersiz kimliktir (ör. "trugo:123")
+    Key                     string  `json:"key"`
+    Provider               string  `json:"provider"`
     ID                      int     `json:"id"`
     StationID              string  `json:"station_id"`
     Name                   string  `json:"name"`
     Brand                  string  `json:"brand"`
     Latitude               float64 `json:"latitude"`
     Longitude              float64 `json:"longitude"`
-    ConnectorList          string  `json:"connector_list"`
+    ConnectorList          string  `json:"connector_list"` // ham değer, hata ayıklama için saklanır
+    Connectors             []models.Connector `json:"connectors,omitempty"`
     ErrorDeviceCount       int     `json:"error_device_count"`
     ACAvailableSocketCount int     `json:"ac_available_sockets_count"`
     DCAvailableSocketCount int     `json:"dc_available_sockets_count"`
     UnavailableDeviceCount int     `json:"unavailable_device_count"`
     TotalConnectorsCount   int     `json:"total_connectors_count"`
     StationColor           string  `json:"station_color"`
+    LastUpdated            *time.Time `json:"last_updated,omitempty"`
+    // ChangedAt, istasyon verisinin katalogda en son değiştiği yenileme zamanıdır
+    ChangedAt              time.Time  `json:"-"`
     // Review için eklenen alanlar
     AverageRating          float64 `json:"average_rating,omitempty"`
     ReviewCount            int     `json:"review_count,omitempty"`
+    // Konum içeren sorgularda istenen noktaya uzaklık (km)
+    DistanceKM             float64 `json:"distance_km,omitempty"`
+    // mode=driving ile yakın istasyon sorgularında sürüş mesafesi ve tahmini varış süresi
+    DrivingDistanceKM      float64    `json:"driving_distance_km,omitempty"`
+    DrivingDurationSeconds int64      `json:"driving_duration_seconds,omitempty"`
+    DrivingDistanceKind    string     `json:"driving_distance_kind,omitempty"` // road ya da straight_line
+    ETAUpdatedAt           *time.Time `json:"eta_updated_at,omitempty"`
+}
+
+// Katalog önbelleğinin durumu
+type CatalogStatus struct {
+    StationCount int        `json:"station_count"`
+    LastRefresh  *time.Time `json:"last_refresh,omitempty"`
+    LastAttempt  *time.Time `json:"last_attempt,omitempty"`
+    LastError    string     `json:"last_error,omitempty"`
+    TTLSeconds   int        `json:"ttl_seconds"`
+    Stale        bool       `json:"stale"`
+    Providers    []ProviderStatus `json:"providers"`
+}
+
+// Tek bir sağlayıcının son yükleme durumu
+type ProviderStatus struct {
+    Name         string     `json:"name"`
+    StationCount int        `json:"station_count"`
+    LastRefresh  *time.Time `json:"last_refresh,omitempty"`
+    LastError    string     `json:"last_error,omitempty"`
+}
+
+// Sağlayıcıdan en son başarıyla alınan veri ve son hata
+type providerState struct {
+    stations    []Station
+    lastRefresh time.Time
+    lastError   error
 }
 
 type StationService struct {
-    stations []Station
-    apiURL   string
+    providers []StationProvider
+    ttl       time.Duration
+
+    // Okuyucular yalnızca catalog'u yükler, kilit almaz
+    catalog atomic.Pointer[stationCatalog]
+
+    // refreshMu aynı anda tek bir yenileme yapılmasını sağlar,
+    // mu ise son deneme bilgilerini ve sağlayıcı durumlarını korur
+    refreshMu   sync.Mutex
+    mu          sync.RWMutex
+    lastAttempt time.Time
+    lastError   error
+    states      map[string]*providerState
+
+    events          *EventHub
+    listeners       []func([]Station, time.Time)
+    changeListeners []func([]StationChange)
 }
 
-func NewStationService() *StationService {
+// NewStationService, verilen sağlayıcıları tek bir katalogda birleştirir.
+// İlk sağlayıcı birincil kabul edilir: ön eksiz ID'ler ona yönlendirilir.
+func NewStationService(providers ...StationProvider) *StationService {
+    if len(providers) == 0 {
+        log.Fatal("En az bir istasyon sağlayıcısı gerekli")
+    }
+
+    states := make(map[string]*providerState, len(providers))
+    for _, p := range providers {
+        if _, exists := states[p.Name()]; exists {
+            log.Fatalf("İstasyon sağlayıcı adı tekrar ediyor: %s", p.Name())
+        }
+        states[p.Name()] = &providerState{}
+    }
+
+    ttl := defaultStationCacheTTL
+    if v := os.Getenv("STATION_CACHE_TTL"); v != "" {
+        d, err := time.ParseDuration(v)
+        if err != nil || d <= 0 {
+            log.Printf("Geçersiz STATION_CACHE_TTL (%q), varsayılan kullanılıyor: %v", v, ttl)
+        } else {
+            ttl = d
+        }
+    }
+
     return &StationService{
-        apiURL: "https://emsp-api.trugo.com.tr/v1/public/csms/stations/fast/",
+        providers: providers,
+        ttl:       ttl,
+        states:    states,
+        events:    NewEventHub(),
+    }
+}
+
+// Start, kataloğu hemen yükler ve ctx iptal edilene kadar her TTL'de yeniler
+func (s *StationService) Start(ctx context.Context) {
+    if err := s.Refresh(ctx); err != nil {
+        log.Printf("İlk istasyon yüklemesi başarısız: %v", err)
     }
+
+    go func() {
+        ticker := time.NewTicker(s.ttl)
+        defer ticker.Stop()
+
+        for {
+            select {
+            case <-ctx.Done():
+                return
+            case <-ticker.C:
+                if err := s.Refresh(ctx); err != nil {
+                    log.Printf("İstasyonlar yenilenirken hata, eski veri kullanılıyor: %v", err)
+                }
+            }
+        }
+    }()
 }
 
-func (s *StationService) loadStations() error {
-    resp, err := http.Get(s.apiURL)
-    if err != nil {
-        return fmt.Errorf("API isteği hatası: %v", err)
+// Refresh, tüm sağlayıcılardan istasyonları yeniden çeker ve yeni bir katalog
+// yayınlar. Başarısız olan sağlayıcının son başarılı verisi kullanılmaya devam
+// eder; hiçbir sağlayıcıdan veri alınamadıysa mevcut katalog korunur.
+func (s *StationService) Refresh(ctx context.Context) error {
+    s.refreshMu.Lock()
+    defer s.refreshMu.Unlock()
+
+    return s.refreshLocked(ctx)
+}
+
+func (s *StationService) refreshLocked(ctx context.Context) error {
+    type fetchResult struct {
+        stations []Station
+        err      error
+    }
+
+    results := make([]fetchResult, len(s.providers))
+    var wg sync.WaitGroup
+    for i, p := range s.providers {
+        wg.Add(1)
+        go func(i int, p StationProvider) {
+            defer wg.Done()
+            stations, err := p.FetchStations(ctx)
+            results[i] = fetchResult{stations: stations, err: err}
+        }(i, p)
     }
-    defer resp.Body.Close()
+    wg.Wait()
 
-    body, err := ioutil.ReadAll(resp.Body)
-    if err != nil {
-        return fmt.Errorf("Response okuma hatası: %v", err)
+    now := time.Now()
+
+    s.mu.Lock()
+    var failures []string
+    var merged []Station
+    loaded := false
+    for i, p := range s.providers {
+        state := s.states[p.Name()]
+        state.lastError = results[i].err
+        if results[i].err != nil {
+            failures = append(failures, fmt.Sprintf("%s: %v", p.Name(), results[i].err))
+        } else {
+            state.stations = namespaceStations(p.Name(), results[i].stations)
+            state.lastRefresh = now
+        }
+        if !state.lastRefresh.IsZero() {
+            loaded = true
+        }
+        merged = append(merged, state.stations...)
     }
 
-    var response TrugoResponse
-    if err := json.Unmarshal(body, &response); err != nil {
-        log.Printf("JSON parse hatası: %v", err)
-        log.Printf("JSON içeriği: %s", string(body))
-        return fmt.Errorf("JSON parse hatası: %v", err)
+    var err error
+    if len(failures) > 0 {
+        err = fmt.Errorf("sağlayıcı hatası: %s", strings.Join(failures, "; "))
     }
+    s.lastAttempt = now
+    s.lastError = err
+    s.mu.Unlock()
+
+    if loaded {
+        stampChangeTimes(s.catalog.Load(), merged, now)
+        next := newStationCatalog(merged, now)
+        prev := s.catalog.Swap(next)
+        s.events.Publish(diffAvailability(prev, next, now)...)
+
+        changes := diffCatalogs(prev, next, now)
+        s.events.Publish(changeEvents(next, changes)...)
 
-    // İstasyonları stations alanından al
-    s.stations = response.Data.Stations
-    log.Printf("%d istasyon yüklendi", len(s.stations))
-    return nil
+        // Dinleyiciler (ör. geçmiş kaydı) yenilemeyi bekletmesin
+        for _, fn := range s.listeners {
+            go fn(next.stations, now)
+        }
+        // Değişiklik dinleyicileri yenileme sırasını korumak için burada,
+        // sırayla çağrılır; işi kendi kuyruklarına alıp hemen dönmelidirler
+        if len(changes) > 0 {
+            for _, fn := range s.changeListeners {
+                fn(changes)
+            }
+        }
+    }
+    return err
 }
 
-func (s *StationService) GetStations() []Station {
-    // İstasyonları yükle (cache mekanizması eklenebilir)
-    if err := s.loadStations(); err != nil {
-        log.Printf("İstasyonlar yüklenirken hata: %v", err)
-        return []Station{}
+// changeEvents, değişiklikleri olay merkezinde yayınlanacak olaylara çevirir
+func changeEvents(next *stationCatalog, changes []StationChange) []StationEvent {
+    events := make([]StationEvent, 0, len(changes))
+    for i := range changes {
+        event := StationEvent{
+            Type:       changes[i].EventType(),
+            StationKey: changes[i].StationKey,
+            Change:     &changes[i],
+            Time:       changes[i].DetectedAt,
+        }
+        // Kaldırılan istasyon yeni katalogda yoktur
+        event.Station = next.find(changes[i].StationKey)
+        events = append(events, event)
     }
-    return s.stations
+    return events
 }
 
-func (s *StationService) GetStation(id string) *Station {
-    // String ID'yi int'e çevir
-    stationID, err := strconv.Atoi(id)
-    if err != nil {
-        log.Printf("Invalid station ID: %v", err)
-        return nil
+// OnChanges, her yenilemede tespit edilen istasyon değişiklikleriyle çağrılacak
+// bir dinleyici ekler. Dinleyiciler yenileme sırasıyla ve yenilemenin içinden
+// çağrılır; uzun işleri kuyruğa alıp hemen dönmelidir. Start'tan önce çağrılmalıdır.
+func (s *StationService) OnChanges(fn func(changes []StationChange)) {
+    s.changeListeners = append(s.changeListeners, fn)
+}
+
+// OnRefresh, her başarılı yenilemeden sonra yeni katalogla çağrılacak bir
+// dinleyici ekler. Verilen dilim paylaşılır, değiştirilmemelidir. Start'tan
+// önce çağrılmalıdır.
+func (s *StationService) OnRefresh(fn func(stations []Station, at time.Time)) {
+    s.listeners = append(s.listeners, fn)
+}
+
+// Events, katalog yenilemelerinde üretilen istasyon olaylarının yayınlandığı merkezdir
+func (s *StationService) Events() *EventHub {
+    return s.events
+}
+
+// namespaceStations, sağlayıcı adını istasyonlara işler ve anahtarları
+// "sağlayıcı:yerel-id" biçimine getirir
+func namespaceStations(provider string, stations []Station) []Station {
+    result := make([]Station, len(stations))
+    for i, station := range stations {
+        localID := station.Key
+        if localID == "" {
+            localID = strconv.Itoa(station.ID)
+        }
+        station.Key = provider + ":" + localID
+        station.Provider = provider
+        if station.Brand == "" {
+            station.Brand = provider
+        }
+        result[i] = station
     }
+    return result
+}
 
-    // İstasyonları yükle
-    if err := s.loadStations(); err != nil {
-        log.Printf("İstasyonlar yüklenirken hata: %v", err)
-        return nil
+// Status, önbelleğin ne kadar güncel olduğunu döndürür
+func (s *StationService) Status() CatalogStatus {
+    status := CatalogStatus{
+        TTLSeconds: int(s.ttl.Seconds()),
+        Stale:      true,
+    }
+
+    if catalog := s.catalog.Load(); catalog != nil {
+        t := catalog.loadedAt
+        status.StationCount = len(catalog.stations)
+        status.LastRefresh = &t
+        status.Stale = time.Since(t) > 2*s.ttl
     }
 
-    for _, station := range s.stations {
-        if station.ID == stationID {
-            stationCopy := station
-            return &stationCopy
+    s.mu.RLock()
+    defer s.mu.RUnlock()
+
+    if !s.lastAttempt.IsZero() {
+        t := s.lastAttempt
+        status.LastAttempt = &t
+    }
+    if s.lastError != nil {
+        status.LastError = s.lastError.Error()
+    }
+
+    for _, p := range s.providers {
+        state := s.states[p.Name()]
+        ps := ProviderStatus{
+            Name:         p.Name(),
+            StationCount: len(state.stations),
+        }
+        if !state.lastRefresh.IsZero() {
+            t := state.lastRefresh
+            ps.LastRefresh = &t
+        }
+        if state.lastError != nil {
+            ps.LastError = state.lastError.Error()
         }
+        status.Providers = append(status.Providers, ps)
     }
-    return nil
+    return status
 }
 
-func (s *StationService) GetNearbyStations(lat, lon float64, limit int) []Station {
-    // İstasyonları yükle
-    if err := s.loadStations(); err != nil {
-        log.Printf("İstasyonlar yüklenirken hata: %v", err)
-        return []Station{}
+// currentCatalog, yayınlanmış son kataloğu döndürür. Hiç yükleme yapılmamışsa
+// (Start çağrılmadıysa) bir kez senkron olarak yükler; yine de başarısız
+// olursa boş bir katalog döner.
+func (s *StationService) currentCatalog() *stationCatalog {
+    if catalog := s.catalog.Load(); catalog != nil {
+        return catalog
     }
 
-    // Mesafe hesaplama ve sıralama işlemleri...
-    type stationDistance struct {
-        station  Station
-        distance float64
+    s.refreshMu.Lock()
+    defer s.refreshMu.Unlock()
+
+    // Kilidi beklerken başka bir istek yüklemiş olabilir
+    if catalog := s.catalog.Load(); catalog != nil {
+        return catalog
     }
 
-    var stationsWithDistance []stationDistance
-    for _, station := range s.stations {
-        dist := calculateHaversineDistance(lat, lon, station.Latitude, station.Longitude)
-        stationsWithDistance = append(stationsWithDistance, stationDistance{
-            station:  station,
-            distance: dist,
-        })
+    if err := s.refreshLocked(context.Background()); err != nil {
+        log.Printf("İstasyonlar yüklenirken hata: %v", err)
+    }
+    if catalog := s.catalog.Load(); catalog != nil {
+        return catalog
     }
+    return newStationCatalog(nil, time.Time{})
+}
 
-    // Mesafeye göre sırala
-    sort.Slice(stationsWithDistance, func(i, j int) bool {
-        return stationsWithDistance[i].distance < stationsWithDistance[j].distance
-    })
+// ResolveKey, dışarıdan gelen istasyon ID'sini katalog anahtarına çevirir.
+// Ön eksiz ID'ler (ör. eski "123" biçimi) birincil sağlayıcıya aittir.
+func (s *StationService) ResolveKey(id string) string {
+    if strings.Contains(id, ":") {
+        return id
+    }
+    return s.providers[0].Name() + ":" + id
+}
 
-    // İstenen sayıda istasyonu döndür
-    var result []Station
-    for i := 0; i < limit && i < len(stationsWithDistance); i++ {
-        result = append(result, stationsWithDistance[i].station)
+// GetStations, katalogdaki istasyonların bir kopyasını döndürür
+func (s *StationService) GetStations() []Station {
+    catalog := s.currentCatalog()
+
+    stations := make([]Station, len(catalog.stations))
+    copy(stations, catalog.stations)
+    return stations
+}
+
+// GetStation, "trugo:123" gibi ad alanlı ya da birincil sağlayıcı için
+// ön eksiz ID ile istasyonu bulur
+func (s *StationService) GetStation(id string) *Station {
+    if id == "" {
+        return nil
     }
+    return s.currentCatalog().find(s.ResolveKey(id))
+}
 
-    return result
+// GetNearbyStations, noktaya en yakın limit kadar istasyonu mesafeleriyle döndürür
+func (s *StationService) GetNearbyStations(lat, lon float64, limit int) []Station {
+    catalog := s.currentCatalog()
+    return catalog.fromHits(catalog.index.nearest(lat, lon, limit))
+}
+
+// GetStationsWithinRadius, noktaya radiusKM içindeki istasyonları yakından uzağa döndürür
+func (s *StationService) GetStationsWithinRadius(lat, lon, radiusKM float64) []Station {
+    catalog := s.currentCatalog()
+    return catalog.fromHits(catalog.index.withinRadius(lat, lon, radiusKM))
+}
+
+// GetStationsInBounds, kutu içindeki istasyonları katalog sırasıyla döndürür.
+// minLon > maxLon ise kutu 180. meridyeni kesiyor kabul edilir.
+func (s *StationService) GetStationsInBounds(minLat, minLon, maxLat, maxLon float64) []Station {
+    catalog := s.currentCatalog()
+
+    indexes := catalog.index.inBounds(minLat, minLon, maxLat, maxLon)
+    stations := make([]Station, len(indexes))
+    for i, idx := range indexes {
+        stations[i] = catalog.stations[idx]
+    }
+    return stations
 }
 
 // Haversine formülü ile iki nokta arası mesafe hesaplama
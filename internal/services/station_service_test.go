@@ -0,0 +1,133 @@
+package services
+
+import (
+    "context"
+    "fmt"
+    "sync"
+    "sync/atomic"
+    "testing"
+)
+
+// fakeProvider, her çağrıda istasyonları biraz kaydırarak yeni bir küme döndürür
+type fakeProvider struct {
+    name  string
+    count int
+    calls atomic.Int64
+}
+
+func (p *fakeProvider) Name() string { return p.name }
+
+func (p *fakeProvider) FetchStations(ctx context.Context) ([]Station, error) {
+    n := p.calls.Add(1)
+    stations := make([]Station, p.count)
+    for i := range stations {
+        stations[i] = Station{
+            ID:                     i + 1,
+            Name:                   fmt.Sprintf("İstasyon %d", i+1),
+            Latitude:               39 + float64(i%100)*0.01,
+            Longitude:              32 + float64(i/100)*0.01,
+            ACAvailableSocketCount: int(n+int64(i)) % 3,
+        }
+    }
+    return stations, nil
+}
+
+// Yenilemeler sürerken yapılan okumalar hep tutarlı bir katalog görmelidir.
+// go test -race ile çalıştırıldığında veri yarışı da denetlenir.
+func TestGetNearbyStationsDuringRefresh(t *testing.T) {
+    provider := &fakeProvider{name: "trugo", count: 500}
+    service := NewStationService(provider)
+    if err := service.Refresh(context.Background()); err != nil {
+        t.Fatal(err)
+    }
+
+    ctx, cancel := context.WithCancel(context.Background())
+    var wg sync.WaitGroup
+    wg.Add(1)
+    go func() {
+        defer wg.Done()
+        for ctx.Err() == nil {
+            if err := service.Refresh(ctx); err != nil && ctx.Err() == nil {
+                t.Errorf("refresh: %v", err)
+            }
+        }
+    }()
+
+    var readers sync.WaitGroup
+    for r := 0; r < 8; r++ {
+        readers.Add(1)
+        go func(r int) {
+            defer readers.Done()
+            for i := 0; i < 100; i++ {
+                lat, lon := 39.2+float64(r)*0.05, 32.1
+                stations := service.GetNearbyStations(lat, lon, 10)
+                if len(stations) != 10 {
+                    t.Errorf("got %d stations, want 10", len(stations))
+                    return
+                }
+                for j := 1; j < len(stations); j++ {
+                    prev := calculateHaversineDistance(lat, lon, stations[j-1].Latitude, stations[j-1].Longitude)
+                    if calculateHaversineDistance(lat, lon, stations[j].Latitude, stations[j].Longitude) < prev {
+                        t.Errorf("stations not ordered by distance at %d", j)
+                        return
+                    }
+                }
+                if service.GetStation(stations[0].Key) == nil {
+                    t.Errorf("station %s missing from catalog", stations[0].Key)
+                    return
+                }
+                if n := len(service.GetStations()); n != provider.count {
+                    t.Errorf("got %d catalog stations, want %d", n, provider.count)
+                    return
+                }
+            }
+        }(r)
+    }
+    readers.Wait()
+    cancel()
+    wg.Wait()
+
+    if provider.calls.Load() < 2 {
+        t.Fatalf("expected concurrent refreshes, got %d", provider.calls.Load())
+    }
+}
+
+// growingProvider, her çağrıda bir istasyon daha ekler
+type growingProvider struct {
+    calls int
+}
+
+func (p *growingProvider) Name() string { return "trugo" }
+
+func (p *growingProvider) FetchStations(ctx context.Context) ([]Station, error) {
+    p.calls++
+    stations := make([]Station, p.calls)
+    for i := range stations {
+        stations[i] = Station{ID: i + 1, Name: fmt.Sprintf("İstasyon %d", i+1), Latitude: 39, Longitude: 32 + float64(i)*0.01}
+    }
+    return stations, nil
+}
+
+// Değişiklik günlüğünün id sırası yenileme sırasını izlemeli; dinleyiciler
+// yenileme dönmeden, yenileme sırasıyla çağrılmalıdır
+func TestChangeListenersRunInRefreshOrder(t *testing.T) {
+    service := NewStationService(&growingProvider{})
+    var added []string
+    service.OnChanges(func(changes []StationChange) {
+        for _, change := range changes {
+            if change.Type == ChangeAdded {
+                added = append(added, change.StationKey)
+            }
+        }
+    })
+
+    for i := 1; i <= 20; i++ {
+        if err := service.Refresh(context.Background()); err != nil {
+            t.Fatal(err)
+        }
+        want := fmt.Sprintf("trugo:%d", i)
+        if i > 1 && (len(added) == 0 || added[len(added)-1] != want) {
+            t.Fatalf("after refresh %d last added = %v, want %s", i, added, want)
+        }
+    }
+}
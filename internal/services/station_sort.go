@@ -0,0 +1,84 @@
+package services
+
+import (
+    "fmt"
+    "sort"
+)
+
+// Desteklenen sıralama alanları
+const (
+    SortByDistance         = "distance"
+    SortByRating           = "rating"
+    SortByAvailableSockets = "available_sockets"
+    SortByName             = "name"
+)
+
+// ParseStationSort, sıralama alanını ve yönünü doğrular. order boşsa alanın
+// doğal yönü kullanılır: mesafe ve isim artan, puan ve boş soket azalan.
+func ParseStationSort(by, order string) (string, bool, error) {
+    desc := false
+    switch by {
+    case "", SortByDistance, SortByName:
+    case SortByRating, SortByAvailableSockets:
+        desc = true
+    default:
+        return "", false, fmt.Errorf("Invalid sort: %s", by)
+    }
+
+    switch order {
+    case "":
+    case "asc":
+        desc = false
+    case "desc":
+        desc = true
+    default:
+        return "", false, fmt.Errorf("Invalid order: %s", order)
+    }
+    return by, desc, nil
+}
+
+// SortStations, istasyonları yerinde sıralar. Eşitlikte anahtar sırası korunur,
+// böylece sayfalar arasında istasyonlar kaymaz. Mesafe sıralaması için
+// DistanceKM alanının doldurulmuş olması gerekir.
+func SortStations(stations []Station, by string, desc bool) {
+    if by == "" {
+        return
+    }
+
+    less := func(a, b Station) (bool, bool) {
+        switch by {
+        case SortByDistance:
+            return a.DistanceKM < b.DistanceKM, a.DistanceKM == b.DistanceKM
+        case SortByRating:
+            return a.AverageRating < b.AverageRating, a.AverageRating == b.AverageRating
+        case SortByAvailableSockets:
+            av, bv := availableSockets(a), availableSockets(b)
+            return av < bv, av == bv
+        default:
+            an, bn := foldText(a.Name), foldText(b.Name)
+            return an < bn, an == bn
+        }
+    }
+
+    sort.SliceStable(stations, func(i, j int) bool {
+        lt, eq := less(stations[i], stations[j])
+        if eq {
+            return stations[i].Key < stations[j].Key
+        }
+        if desc {
+            return !lt
+        }
+        return lt
+    })
+}
+
+// WithDistances, her istasyona verilen noktaya kuş uçuşu mesafesini yazar
+func WithDistances(stations []Station, lat, lon float64) {
+    for i := range stations {
+        stations[i].DistanceKM = calculateHaversineDistance(lat, lon, stations[i].Latitude, stations[i].Longitude)
+    }
+}
+
+func availableSockets(s Station) int {
+    return s.ACAvailableSocketCount + s.DCAvailableSocketCount
+}
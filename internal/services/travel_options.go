@@ -0,0 +1,124 @@
+package services
+
+import (
+    "fmt"
+    "strconv"
+    "time"
+
+    "googlemaps.github.io/maps"
+)
+
+// Önbellek anahtarında kalkış/varış saatleri bu aralığa yuvarlanır
+const travelTimeCacheBucket = 15 * time.Minute
+
+// Kalkış saatinin geçmişte kalmasına tolerans (istemci saat farkı)
+const travelTimeSkew = time.Minute
+
+// Trafik modelleri (Google Directions/Distance Matrix)
+var trafficModels = map[string]bool{
+    string(maps.TrafficModelBestGuess):   true,
+    string(maps.TrafficModelOptimistic):  true,
+    string(maps.TrafficModelPessimistic): true,
+}
+
+// TravelOptions, zamana bağlı yolculuk tahmini seçenekleridir. Sıfır değer
+// "şimdi, trafiksiz, tek rota" anlamına gelir.
+type TravelOptions struct {
+    DepartNow     bool
+    DepartureTime time.Time
+    ArrivalTime   time.Time
+    TrafficModel  string // best_guess, optimistic, pessimistic
+    Alternatives  bool
+}
+
+// ParseTravelTime, "now", Unix saniyesi ya da RFC3339 zamanı çözer
+func ParseTravelTime(s string) (t time.Time, now bool, err error) {
+    if s == "now" {
+        return time.Time{}, true, nil
+    }
+    if unix, err := strconv.ParseInt(s, 10, 64); err == nil {
+        return time.Unix(unix, 0), false, nil
+    }
+    t, err = time.Parse(time.RFC3339, s)
+    if err != nil {
+        return time.Time{}, false, fmt.Errorf("expected now, unix seconds or RFC3339 time")
+    }
+    return t, false, nil
+}
+
+// Validate, birbirini dışlayan ve geçersiz seçenekleri reddeder
+func (o TravelOptions) Validate() error {
+    departure := o.DepartNow || !o.DepartureTime.IsZero()
+    minTime := time.Now().Add(-travelTimeSkew)
+
+    switch {
+    case departure && !o.ArrivalTime.IsZero():
+        return fmt.Errorf("departure_time and arrival_time cannot be combined")
+    case !o.DepartureTime.IsZero() && o.DepartureTime.Before(minTime):
+        return fmt.Errorf("departure_time must not be in the past")
+    case !o.ArrivalTime.IsZero() && o.ArrivalTime.Before(minTime):
+        return fmt.Errorf("arrival_time must not be in the past")
+    case o.TrafficModel != "" && !trafficModels[o.TrafficModel]:
+        return fmt.Errorf("traffic_model must be best_guess, optimistic or pessimistic")
+    case o.TrafficModel != "" && !departure:
+        return fmt.Errorf("traffic_model requires departure_time")
+    }
+    return nil
+}
+
+// trafficAware, trafik tahmini istenip istenmediğini bildirir. Google trafikli
+// süreyi yalnızca kalkış saati verildiğinde hesaplar.
+func (o TravelOptions) trafficAware() bool {
+    return o.DepartNow || !o.DepartureTime.IsZero()
+}
+
+// googleDepartureTime, Google'ın beklediği kalkış değeridir ("now" ya da Unix saniyesi)
+func (o TravelOptions) googleDepartureTime() string {
+    if o.DepartNow {
+        return "now"
+    }
+    if o.DepartureTime.IsZero() {
+        return ""
+    }
+    return strconv.FormatInt(o.DepartureTime.Unix(), 10)
+}
+
+func (o TravelOptions) googleArrivalTime() string {
+    if o.ArrivalTime.IsZero() {
+        return ""
+    }
+    return strconv.FormatInt(o.ArrivalTime.Unix(), 10)
+}
+
+// cacheKeyParts, seçenekleri önbellek anahtarına eklenecek parçalara çevirir.
+// "now" istekleri o anki zaman dilimine düşer; trafik bilgisi dilim değişince yenilenir.
+func (o TravelOptions) cacheKeyParts() []string {
+    var parts []string
+    switch {
+    case o.DepartNow:
+        parts = append(parts, "dep="+bucketUnix(time.Now()))
+    case !o.DepartureTime.IsZero():
+        parts = append(parts, "dep="+bucketUnix(o.DepartureTime))
+    case !o.ArrivalTime.IsZero():
+        parts = append(parts, "arr="+bucketUnix(o.ArrivalTime))
+    }
+    if o.TrafficModel != "" {
+        parts = append(parts, "tm="+o.TrafficModel)
+    }
+    if o.Alternatives {
+        parts = append(parts, "alt")
+    }
+    return parts
+}
+
+func bucketUnix(t time.Time) string {
+    return strconv.FormatInt(t.Truncate(travelTimeCacheBucket).Unix(), 10)
+}
+
+// trafficUnavailableWarning, trafik istendiği halde sağlayıcı desteklemiyorsa uyarı döndürür
+func (o TravelOptions) trafficUnavailableWarning(provider string) string {
+    if !o.trafficAware() {
+        return ""
+    }
+    return fmt.Sprintf("Traffic-aware duration is not available from %s", provider)
+}
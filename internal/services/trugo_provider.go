@@ -0,0 +1,81 @@
+package services
+
+import (
+    "context"
+    "encoding/json"
+    "fmt"
+    "io/ioutil"
+    "log"
+    "net/http"
+    "time"
+)
+
+const trugoStationsURL = "https://emsp-api.trugo.com.tr/v1/public/csms/stations/fast/"
+
+// API yanıt yapısı
+type TrugoResponse struct {
+    Status   string         `json:"status"`
+    Message  string         `json:"message"`
+    Data     StationsData   `json:"data"`
+    Options  interface{}    `json:"options"`
+}
+
+// Stations verisi için ara yapı
+type StationsData struct {
+    Stations []Station `json:"stations"`
+}
+
+// TrugoProvider, Trugo'nun herkese açık istasyon listesini okur
+type TrugoProvider struct {
+    apiURL string
+    client *http.Client
+}
+
+func NewTrugoProvider() *TrugoProvider {
+    return &TrugoProvider{
+        apiURL: trugoStationsURL,
+        client: &http.Client{Timeout: 30 * time.Second},
+    }
+}
+
+func (p *TrugoProvider) Name() string {
+    return "trugo"
+}
+
+func (p *TrugoProvider) FetchStations(ctx context.Context) ([]Station, error) {
+    req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.apiURL, nil)
+    if err != nil {
+        return nil, fmt.Errorf("API isteği oluşturulamadı: %v", err)
+    }
+
+    resp, err := p.client.Do(req)
+    if err != nil {
+        return nil, fmt.Errorf("API isteği hatası: %v", err)
+    }
+    defer resp.Body.Close()
+
+    if resp.StatusCode != http.StatusOK {
+        return nil, fmt.Errorf("API beklenmeyen durum kodu döndürdü: %d", resp.StatusCode)
+    }
+
+    body, err := ioutil.ReadAll(resp.Body)
+    if err != nil {
+        return nil, fmt.Errorf("Response okuma hatası: %v", err)
+    }
+
+    var response TrugoResponse
+    if err := json.Unmarshal(body, &response); err != nil {
+        log.Printf("JSON parse hatası: %v", err)
+        log.Printf("JSON içeriği: %s", string(body))
+        return nil, fmt.Errorf("JSON parse hatası: %v", err)
+    }
+
+    // İstasyonları stations alanından al, ham soket listesini tipli sokete çevir
+    stations := response.Data.Stations
+    for i := range stations {
+        stations[i].Connectors = ParseConnectorList(stations[i].ConnectorList)
+    }
+
+    log.Printf("Trugo: %d istasyon yüklendi", len(stations))
+    return stations, nil
+}
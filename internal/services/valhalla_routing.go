@@ -0,0 +1,253 @@
+package services
+
+import (
+    "bytes"
+    "context"
+    "encoding/json"
+    "fmt"
+    "io/ioutil"
+    "net/http"
+    "strconv"
+    "strings"
+    "time"
+
+    "googlemaps.github.io/maps"
+)
+
+// ValhallaRoutingProvider, kendi sunucumuzda çalışan bir Valhalla HTTP API'sini kullanır
+type ValhallaRoutingProvider struct {
+    baseURL string
+    client  *http.Client
+    // Valhalla kalkış/varış saatini konumun yerel saati olarak yorumlar;
+    // saatler gönderilmeden önce bu dilime çevrilir
+    zone *time.Location
+}
+
+// NewValhallaRoutingProvider, zone nil ise saatleri UTC olarak gönderir
+func NewValhallaRoutingProvider(baseURL string, client *http.Client, zone *time.Location) *ValhallaRoutingProvider {
+    if client == nil {
+        client = &http.Client{Timeout: routingRequestTimeout}
+    }
+    if zone == nil {
+        zone = time.UTC
+    }
+    return &ValhallaRoutingProvider{
+        baseURL: strings.TrimRight(baseURL, "/"),
+        client:  client,
+        zone:    zone,
+    }
+}
+
+type valhallaLocation struct {
+    Lat float64 `json:"lat"`
+    Lon float64 `json:"lon"`
+}
+
+type valhallaRequest struct {
+    Locations         []valhallaLocation `json:"locations"`
+    Costing           string             `json:"costing"`
+    DirectionsOptions struct {
+        Units string `json:"units"`
+    } `json:"directions_options"`
+    DateTime   *valhallaDateTime `json:"date_time,omitempty"`
+    Alternates int               `json:"alternates,omitempty"`
+}
+
+// Valhalla zamana bağlı rota seçeneği: type 1 kalkış, 2 varış saatidir.
+// value, konumun yerel saati olarak yorumlanır.
+type valhallaDateTime struct {
+    Type  int    `json:"type"`
+    Value string `json:"value,omitempty"`
+}
+
+// Valhalla'nın alternatif rota sayısı üst sınırı
+const valhallaMaxAlternates = 2
+
+// Valhalla route yanıtı. Uzunluklar km, süreler saniyedir; shape 1e6
+// hassasiyetli kodlu polyline'dır.
+type valhallaResponse struct {
+    Trip       valhallaTrip `json:"trip"`
+    Alternates []struct {
+        Trip valhallaTrip `json:"trip"`
+    } `json:"alternates"`
+    ErrorCode int    `json:"error_code"`
+    Error     string `json:"error"`
+}
+
+type valhallaTrip struct {
+    Status        int    `json:"status"`
+    StatusMessage string `json:"status_message"`
+    Summary       struct {
+        Length float64 `json:"length"`
+        Time   float64 `json:"time"`
+    } `json:"summary"`
+    Legs []struct {
+        Shape   string `json:"shape"`
+        Summary struct {
+            Length float64 `json:"length"`
+            Time   float64 `json:"time"`
+        } `json:"summary"`
+        Maneuvers []struct {
+            Instruction     string  `json:"instruction"`
+            Length          float64 `json:"length"`
+            Time            float64 `json:"time"`
+            BeginShapeIndex int     `json:"begin_shape_index"`
+            EndShapeIndex   int     `json:"end_shape_index"`
+        } `json:"maneuvers"`
+    } `json:"legs"`
+}
+
+func (p *ValhallaRoutingProvider) Name() string {
+    return "valhalla"
+}
+
+// Route, Valhalla rotasını döndürür. Kalkış/varış saati verilirse Valhalla
+// tarihsel hızlarla zamana bağlı süre hesaplar; canlı trafik kullanmaz.
+func (p *ValhallaRoutingProvider) Route(ctx context.Context, origin, destination maps.LatLng, opts TravelOptions) (*RouteResult, error) {
+    resp, err := p.fetchRoute(ctx, origin, destination, opts)
+    if err != nil {
+        return nil, err
+    }
+
+    result, err := valhallaTripResult(resp.Trip)
+    if err != nil {
+        return nil, err
+    }
+    for _, alt := range resp.Alternates {
+        converted, err := valhallaTripResult(alt.Trip)
+        if err != nil {
+            return nil, err
+        }
+        result.Alternatives = append(result.Alternatives, converted)
+    }
+    if warning := opts.trafficUnavailableWarning(p.Name()); warning != "" {
+        result.Warnings = append(result.Warnings, warning)
+    }
+    return result, nil
+}
+
+func valhallaTripResult(trip valhallaTrip) (*RouteResult, error) {
+    result := &RouteResult{
+        DistanceMeters:  int(trip.Summary.Length * 1000),
+        DurationSeconds: int64(trip.Summary.Time),
+        Legs:            make([]RouteLeg, 0, len(trip.Legs)),
+    }
+
+    var overview []maps.LatLng
+    for _, leg := range trip.Legs {
+        shape, err := decodePolyline6(leg.Shape)
+        if err != nil {
+            return nil, fmt.Errorf("Valhalla rota çizgisi çözülemedi: %v", err)
+        }
+        overview = append(overview, shape...)
+
+        out := RouteLeg{
+            DistanceMeters:  int(leg.Summary.Length * 1000),
+            DurationSeconds: int64(leg.Summary.Time),
+            Steps:           make([]RouteStep, 0, len(leg.Maneuvers)),
+        }
+        if len(shape) > 0 {
+            out.Start, out.End = shape[0], shape[len(shape)-1]
+        }
+        for _, m := range leg.Maneuvers {
+            begin := minInt(maxInt(m.BeginShapeIndex, 0), len(shape))
+            end := minInt(maxInt(m.EndShapeIndex+1, begin), len(shape))
+            points := shape[begin:end]
+
+            step := RouteStep{
+                Instruction:     m.Instruction,
+                DistanceMeters:  int(m.Length * 1000),
+                DurationSeconds: int64(m.Time),
+                Polyline:        RouteLine{Points: points},
+            }
+            if len(points) > 0 {
+                step.Start, step.End = points[0], points[len(points)-1]
+            }
+            out.Steps = append(out.Steps, step)
+        }
+        result.Legs = append(result.Legs, out)
+    }
+
+    result.Polyline = RouteLine{Points: overview}
+    result.Bounds = boundsOf(overview)
+    return result, nil
+}
+
+func (p *ValhallaRoutingProvider) Distance(ctx context.Context, origin, destination maps.LatLng, opts TravelOptions) (*DistanceResult, error) {
+    opts.Alternatives = false
+    resp, err := p.fetchRoute(ctx, origin, destination, opts)
+    if err != nil {
+        return nil, err
+    }
+    result := &DistanceResult{
+        Distance:       resp.Trip.Summary.Length,
+        Duration:       int64(resp.Trip.Summary.Time),
+        Source:         p.Name(),
+        UpstreamStatus: strconv.Itoa(resp.Trip.Status),
+    }
+    if warning := opts.trafficUnavailableWarning(p.Name()); warning != "" {
+        result.Warnings = append(result.Warnings, warning)
+    }
+    return result, nil
+}
+
+func (p *ValhallaRoutingProvider) fetchRoute(ctx context.Context, origin, destination maps.LatLng, opts TravelOptions) (*valhallaResponse, error) {
+    payload := valhallaRequest{
+        Locations: []valhallaLocation{
+            {Lat: origin.Lat, Lon: origin.Lng},
+            {Lat: destination.Lat, Lon: destination.Lng},
+        },
+        Costing: "auto",
+    }
+    payload.DirectionsOptions.Units = "kilometers"
+
+    const valhallaTimeLayout = "2006-01-02T15:04"
+    switch {
+    case opts.DepartNow:
+        payload.DateTime = &valhallaDateTime{Type: 0}
+    case !opts.DepartureTime.IsZero():
+        payload.DateTime = &valhallaDateTime{Type: 1, Value: opts.DepartureTime.In(p.zone).Format(valhallaTimeLayout)}
+    case !opts.ArrivalTime.IsZero():
+        payload.DateTime = &valhallaDateTime{Type: 2, Value: opts.ArrivalTime.In(p.zone).Format(valhallaTimeLayout)}
+    }
+    if opts.Alternatives {
+        payload.Alternates = valhallaMaxAlternates
+    }
+
+    body, err := json.Marshal(payload)
+    if err != nil {
+        return nil, err
+    }
+
+    req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.baseURL+"/route", bytes.NewReader(body))
+    if err != nil {
+        return nil, fmt.Errorf("Valhalla isteği oluşturulamadı: %v", err)
+    }
+    req.Header.Set("Content-Type", "application/json")
+
+    resp, err := p.client.Do(req)
+    if err != nil {
+        return nil, fmt.Errorf("Valhalla isteği hatası: %v", err)
+    }
+    defer resp.Body.Close()
+
+    respBody, err := ioutil.ReadAll(resp.Body)
+    if err != nil {
+        return nil, fmt.Errorf("Valhalla yanıtı okunamadı: %v", err)
+    }
+
+    var result valhallaResponse
+    if err := json.Unmarshal(respBody, &result); err != nil {
+        return nil, fmt.Errorf("Valhalla beklenmeyen yanıt döndürdü (%d): %v", resp.StatusCode, err)
+    }
+    if resp.StatusCode != http.StatusOK || result.Error != "" {
+        return nil, &RoutingError{
+            Status: strconv.Itoa(result.ErrorCode),
+            Err:    fmt.Errorf("Valhalla hata döndürdü: %s", result.Error),
+        }
+    }
+    if len(result.Trip.Legs) == 0 {
+        return nil, fmt.Errorf("no route found")
+    }
+    return &result, nil
+}
@@ -0,0 +1,79 @@
+package services
+
+import (
+    "context"
+    "encoding/json"
+    "net/http"
+    "net/http/httptest"
+    "testing"
+    "time"
+)
+
+func TestValhallaDateTimeInLocalZone(t *testing.T) {
+    zone, err := time.LoadLocation("Europe/Istanbul")
+    if err != nil {
+        t.Skip(err)
+    }
+
+    var got valhallaRequest
+    server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+            t.Errorf("decode request: %v", err)
+        }
+        w.Write([]byte(`{"trip":{"status":0,"summary":{"length":450.5,"time":18000},"legs":[{"shape":""}]}}`))
+    }))
+    defer server.Close()
+
+    p := NewValhallaRoutingProvider(server.URL, server.Client(), zone)
+    // 06:30 UTC, İstanbul'da 09:30'dur
+    departure := time.Date(2026, 11, 2, 6, 30, 0, 0, time.UTC)
+    tests := []struct {
+        name string
+        opts TravelOptions
+        want valhallaDateTime
+    }{
+        {"departure", TravelOptions{DepartureTime: departure}, valhallaDateTime{Type: 1, Value: "2026-11-02T09:30"}},
+        {"arrival", TravelOptions{ArrivalTime: departure.In(time.FixedZone("EST", -5*3600))}, valhallaDateTime{Type: 2, Value: "2026-11-02T09:30"}},
+        {"now", TravelOptions{DepartNow: true}, valhallaDateTime{Type: 0}},
+    }
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            got = valhallaRequest{}
+            result, err := p.Distance(context.Background(), testOrigin, testDestination, tt.opts)
+            if err != nil {
+                t.Fatal(err)
+            }
+            if got.DateTime == nil || *got.DateTime != tt.want {
+                t.Errorf("date_time = %+v, want %+v", got.DateTime, tt.want)
+            }
+            if result.Distance != 450.5 {
+                t.Errorf("distance = %v", result.Distance)
+            }
+        })
+    }
+}
+
+func TestValhallaDistanceTrafficWarning(t *testing.T) {
+    server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        w.Write([]byte(`{"trip":{"status":0,"summary":{"length":12.3,"time":900},"legs":[{"shape":""}]}}`))
+    }))
+    defer server.Close()
+    p := NewValhallaRoutingProvider(server.URL, server.Client(), nil)
+
+    result, err := p.Distance(context.Background(), testOrigin, testDestination, TravelOptions{})
+    if err != nil {
+        t.Fatal(err)
+    }
+    if len(result.Warnings) != 0 {
+        t.Errorf("warnings without traffic = %v", result.Warnings)
+    }
+
+    opts := TravelOptions{DepartNow: true}
+    result, err = p.Distance(context.Background(), testOrigin, testDestination, opts)
+    if err != nil {
+        t.Fatal(err)
+    }
+    if len(result.Warnings) != 1 || result.Warnings[0] != opts.trafficUnavailableWarning("valhalla") {
+        t.Errorf("warnings = %v", result.Warnings)
+    }
+}
@@ -0,0 +1,262 @@
+package services
+
+import (
+    "bytes"
+    "context"
+    "crypto/hmac"
+    "crypto/sha256"
+    "encoding/hex"
+    "encoding/json"
+    "fmt"
+    "io"
+    "io/ioutil"
+    "log"
+    "net/http"
+    "strconv"
+    "sync"
+    "time"
+)
+
+const (
+    webhookMaxAttempts  = 8
+    webhookBaseBackoff  = 10 * time.Second
+    webhookMaxBackoff   = time.Hour
+    webhookPollInterval = 5 * time.Second
+    webhookBatchSize    = 50
+    webhookWorkers      = 8
+    // Claim edilen kaydın başka bir yoklamada tekrar alınmaması için süre
+    webhookLease       = 2 * time.Minute
+    webhookEventBuffer = 4096
+)
+
+// webhookQueue, dağıtıcının teslimat kuyruğundan beklediği işlemlerdir.
+// Üretimde WebhookStore (Postgres) kullanılır.
+type webhookQueue interface {
+    enqueue(ctx context.Context, eventType string, payload []byte) (int64, error)
+    claimDue(ctx context.Context, limit int, lease time.Duration) ([]claimedDelivery, error)
+    recordAttempt(ctx context.Context, id int64, delivered bool, statusCode int, attemptErr string, nextAttempt time.Time) error
+}
+
+// WebhookDispatcher, olay merkezindeki olayları webhook teslimatlarına çevirir
+// ve bekleyen teslimatları üstel geri çekilmeyle yeniden dener.
+// Her gövde aboneliğin gizli anahtarıyla HMAC-SHA256 imzalanır:
+// X-Webhook-Signature: sha256=hex(HMAC(secret, timestamp + "." + body))
+type WebhookDispatcher struct {
+    store  webhookQueue
+    events *EventHub
+    client *http.Client
+    wake   chan struct{}
+}
+
+func NewWebhookDispatcher(store *WebhookStore, events *EventHub, client *http.Client) *WebhookDispatcher {
+    if client == nil {
+        client = &http.Client{Timeout: 10 * time.Second}
+    }
+    return &WebhookDispatcher{
+        store:  store,
+        events: events,
+        client: client,
+        wake:   make(chan struct{}, 1),
+    }
+}
+
+// Webhook gövdesi
+type webhookPayload struct {
+    ID        string       `json:"id"`
+    Type      string       `json:"type"`
+    CreatedAt time.Time    `json:"created_at"`
+    Data      StationEvent `json:"data"`
+}
+
+// webhookType, olay merkezindeki olay tipini dışarıya açılan webhook tipine eşler
+func webhookType(event StationEvent) (string, bool) {
+    switch event.Type {
+    case EventAvailabilityChanged,
+        "station_" + ChangeWentOffline,
+        "station_" + ChangeCameOnline:
+        return WebhookStationStatusChanged, true
+    case "station_" + ChangeAdded:
+        return WebhookStationAdded, true
+    case "station_" + ChangeRemoved:
+        return WebhookStationRemoved, true
+    case EventReviewCreated:
+        return WebhookReviewCreated, true
+    }
+    return "", false
+}
+
+// Start, olay dinleyicisini ve teslimat döngüsünü ctx iptal edilene kadar çalıştırır
+func (d *WebhookDispatcher) Start(ctx context.Context) {
+    sub := d.events.Subscribe(webhookEventBuffer)
+
+    go func() {
+        defer sub.Close()
+        var reportedDrops int64
+        for {
+            select {
+            case <-ctx.Done():
+                return
+            case event, ok := <-sub.C:
+                if !ok {
+                    return
+                }
+                d.enqueue(ctx, event)
+                if dropped := sub.Dropped(); dropped > reportedDrops {
+                    log.Printf("Webhook kuyruğu dolu, %d olay düşürüldü", dropped-reportedDrops)
+                    reportedDrops = dropped
+                }
+            }
+        }
+    }()
+
+    go func() {
+        ticker := time.NewTicker(webhookPollInterval)
+        defer ticker.Stop()
+        for {
+            d.deliverDue(ctx)
+            select {
+            case <-ctx.Done():
+                return
+            case <-ticker.C:
+            case <-d.wake:
+            }
+        }
+    }()
+}
+
+func (d *WebhookDispatcher) enqueue(ctx context.Context, event StationEvent) {
+    eventType, ok := webhookType(event)
+    if !ok {
+        return
+    }
+
+    id, err := randomHex(16)
+    if err != nil {
+        log.Printf("Webhook olayı oluşturulamadı: %v", err)
+        return
+    }
+    payload, err := json.Marshal(webhookPayload{
+        ID:        id,
+        Type:      eventType,
+        CreatedAt: event.Time,
+        Data:      event,
+    })
+    if err != nil {
+        log.Printf("Webhook olayı oluşturulamadı: %v", err)
+        return
+    }
+
+    n, err := d.store.enqueue(ctx, eventType, payload)
+    if err != nil {
+        log.Printf("Webhook teslimatı kuyruğa alınamadı: %v", err)
+        return
+    }
+    if n > 0 {
+        select {
+        case d.wake <- struct{}{}:
+        default:
+        }
+    }
+}
+
+// deliverDue, zamanı gelen teslimatları sınırlı sayıda eşzamanlı işçiyle gönderir
+func (d *WebhookDispatcher) deliverDue(ctx context.Context) {
+    for {
+        claimed, err := d.store.claimDue(ctx, webhookBatchSize, webhookLease)
+        if err != nil {
+            if ctx.Err() == nil {
+                log.Printf("Bekleyen webhook teslimatları alınamadı: %v", err)
+            }
+            return
+        }
+        if len(claimed) == 0 {
+            return
+        }
+
+        sem := make(chan struct{}, webhookWorkers)
+        var wg sync.WaitGroup
+        for _, delivery := range claimed {
+            wg.Add(1)
+            sem <- struct{}{}
+            go func(delivery claimedDelivery) {
+                defer wg.Done()
+                defer func() { <-sem }()
+                d.attempt(ctx, delivery)
+            }(delivery)
+        }
+        wg.Wait()
+
+        if len(claimed) < webhookBatchSize {
+            return
+        }
+    }
+}
+
+func (d *WebhookDispatcher) attempt(ctx context.Context, delivery claimedDelivery) {
+    statusCode, err := d.send(ctx, delivery)
+    delivered := err == nil
+
+    var next time.Time
+    errText := ""
+    if !delivered {
+        errText = err.Error()
+        if attempts := delivery.attempts + 1; attempts < webhookMaxAttempts {
+            next = time.Now().Add(webhookBackoff(attempts))
+        } else {
+            log.Printf("Webhook teslimatı %d %d denemeden sonra dead-letter listesine alındı: %v", delivery.id, attempts, err)
+        }
+    }
+
+    if err := d.store.recordAttempt(ctx, delivery.id, delivered, statusCode, errText, next); err != nil {
+        log.Printf("Webhook denemesi kaydedilemedi: %v", err)
+    }
+}
+
+func (d *WebhookDispatcher) send(ctx context.Context, delivery claimedDelivery) (int, error) {
+    timestamp := time.Now().Unix()
+
+    req, err := http.NewRequestWithContext(ctx, http.MethodPost, delivery.url, bytes.NewReader(delivery.payload))
+    if err != nil {
+        return 0, fmt.Errorf("istek oluşturulamadı: %v", err)
+    }
+    req.Header.Set("Content-Type", "application/json")
+    req.Header.Set("User-Agent", "charging-stations-webhooks/1.0")
+    req.Header.Set("X-Webhook-Event", delivery.eventType)
+    req.Header.Set("X-Webhook-Delivery", strconv.FormatInt(delivery.id, 10))
+    req.Header.Set("X-Webhook-Timestamp", strconv.FormatInt(timestamp, 10))
+    req.Header.Set("X-Webhook-Signature", SignWebhook(delivery.secret, timestamp, delivery.payload))
+
+    resp, err := d.client.Do(req)
+    if err != nil {
+        return 0, err
+    }
+    defer resp.Body.Close()
+    io.Copy(ioutil.Discard, io.LimitReader(resp.Body, 64<<10))
+
+    if resp.StatusCode < 200 || resp.StatusCode > 299 {
+        return resp.StatusCode, fmt.Errorf("alıcı %d döndürdü", resp.StatusCode)
+    }
+    return resp.StatusCode, nil
+}
+
+// SignWebhook, alıcıların doğrulayacağı imzayı üretir. Zaman damgası imzaya
+// dahil edildiği için eski bir gövde tekrar oynatılamaz.
+func SignWebhook(secret string, timestamp int64, body []byte) string {
+    mac := hmac.New(sha256.New, []byte(secret))
+    mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+    mac.Write([]byte("."))
+    mac.Write(body)
+    return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// webhookBackoff: 10s, 20s, 40s, ... en fazla 1 saat
+func webhookBackoff(attempts int) time.Duration {
+    backoff := webhookBaseBackoff
+    for i := 1; i < attempts && backoff < webhookMaxBackoff; i++ {
+        backoff *= 2
+    }
+    if backoff > webhookMaxBackoff {
+        backoff = webhookMaxBackoff
+    }
+    return backoff
+}
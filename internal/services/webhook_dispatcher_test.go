@@ -0,0 +1,185 @@
+package services
+
+import (
+    "context"
+    "crypto/hmac"
+    "crypto/sha256"
+    "encoding/hex"
+    "io/ioutil"
+    "net/http"
+    "net/http/httptest"
+    "strconv"
+    "sync"
+    "testing"
+    "time"
+)
+
+// fakeWebhookQueue, tek bir teslimatı bellekte tutar. Zaman now alanıyla
+// ilerletilir; böylece geri çekilme süreleri beklenmeden denenebilir.
+type fakeWebhookQueue struct {
+    mu       sync.Mutex
+    now      time.Time
+    delivery claimedDelivery
+    next     time.Time
+    status   string
+    lastCode int
+    backoffs []time.Duration
+}
+
+func (q *fakeWebhookQueue) enqueue(ctx context.Context, eventType string, payload []byte) (int64, error) {
+    return 0, nil
+}
+
+func (q *fakeWebhookQueue) claimDue(ctx context.Context, limit int, lease time.Duration) ([]claimedDelivery, error) {
+    q.mu.Lock()
+    defer q.mu.Unlock()
+    if q.status != DeliveryPending || q.next.After(q.now) {
+        return nil, nil
+    }
+    q.next = q.now.Add(lease)
+    return []claimedDelivery{q.delivery}, nil
+}
+
+func (q *fakeWebhookQueue) recordAttempt(ctx context.Context, id int64, delivered bool, statusCode int, attemptErr string, nextAttempt time.Time) error {
+    q.mu.Lock()
+    defer q.mu.Unlock()
+    q.delivery.attempts++
+    q.lastCode = statusCode
+    switch {
+    case delivered:
+        q.status = DeliveryDelivered
+    case nextAttempt.IsZero():
+        q.status = DeliveryDead
+    default:
+        // Geri çekilme gerçek saate göre hesaplanır; sahte saate taşınır
+        backoff := time.Until(nextAttempt).Round(time.Second)
+        q.backoffs = append(q.backoffs, backoff)
+        q.next = q.now.Add(backoff)
+    }
+    return nil
+}
+
+// advance, sahte saati bir sonraki denemeye getirir
+func (q *fakeWebhookQueue) advance() {
+    q.mu.Lock()
+    defer q.mu.Unlock()
+    q.now = q.next
+}
+
+// webhookReceiver, imzayı doğrulayan ve ilk failures isteğe 503 dönen bir alıcıdır
+func webhookReceiver(t *testing.T, secret string, failures int) (*httptest.Server, *int) {
+    var mu sync.Mutex
+    requests := 0
+    server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        body, _ := ioutil.ReadAll(r.Body)
+
+        mac := hmac.New(sha256.New, []byte(secret))
+        mac.Write([]byte(r.Header.Get("X-Webhook-Timestamp") + "." + string(body)))
+        want := "sha256=" + hex.EncodeToString(mac.Sum(nil))
+        if got := r.Header.Get("X-Webhook-Signature"); !hmac.Equal([]byte(got), []byte(want)) {
+            t.Errorf("signature = %q, want %q", got, want)
+        }
+        if ts, err := strconv.ParseInt(r.Header.Get("X-Webhook-Timestamp"), 10, 64); err != nil || time.Since(time.Unix(ts, 0)) > time.Minute {
+            t.Errorf("invalid timestamp %q", r.Header.Get("X-Webhook-Timestamp"))
+        }
+        if r.Header.Get("X-Webhook-Event") != WebhookStationAdded {
+            t.Errorf("event = %q", r.Header.Get("X-Webhook-Event"))
+        }
+
+        mu.Lock()
+        requests++
+        fail := requests <= failures
+        mu.Unlock()
+        if fail {
+            w.WriteHeader(http.StatusServiceUnavailable)
+            return
+        }
+        w.WriteHeader(http.StatusNoContent)
+    }))
+    return server, &requests
+}
+
+func newFakeWebhookQueue(url, secret string) *fakeWebhookQueue {
+    now := time.Now()
+    return &fakeWebhookQueue{
+        now:    now,
+        next:   now,
+        status: DeliveryPending,
+        delivery: claimedDelivery{
+            id:        1,
+            eventType: WebhookStationAdded,
+            payload:   []byte(`{"id":"abc","type":"station.added"}`),
+            url:       url,
+            secret:    secret,
+        },
+    }
+}
+
+func TestWebhookDispatcherRetriesWithBackoff(t *testing.T) {
+    server, requests := webhookReceiver(t, "s3cret", 2)
+    defer server.Close()
+
+    queue := newFakeWebhookQueue(server.URL, "s3cret")
+    d := NewWebhookDispatcher(nil, NewEventHub(), server.Client())
+    d.store = queue
+
+    for i := 0; i < 5 && queue.status == DeliveryPending; i++ {
+        d.deliverDue(context.Background())
+        queue.advance()
+    }
+
+    if queue.status != DeliveryDelivered || queue.lastCode != http.StatusNoContent {
+        t.Fatalf("status = %s (%d), want delivered", queue.status, queue.lastCode)
+    }
+    if *requests != 3 || queue.delivery.attempts != 3 {
+        t.Errorf("requests = %d, attempts = %d, want 3", *requests, queue.delivery.attempts)
+    }
+    want := []time.Duration{webhookBaseBackoff, 2 * webhookBaseBackoff}
+    if len(queue.backoffs) != len(want) || queue.backoffs[0] != want[0] || queue.backoffs[1] != want[1] {
+        t.Errorf("backoffs = %v, want %v", queue.backoffs, want)
+    }
+}
+
+func TestWebhookDispatcherDeadLettersAfterMaxAttempts(t *testing.T) {
+    server, requests := webhookReceiver(t, "s3cret", webhookMaxAttempts+10)
+    defer server.Close()
+
+    queue := newFakeWebhookQueue(server.URL, "s3cret")
+    d := NewWebhookDispatcher(nil, NewEventHub(), server.Client())
+    d.store = queue
+
+    for i := 0; i < webhookMaxAttempts+3; i++ {
+        d.deliverDue(context.Background())
+        queue.advance()
+    }
+
+    if queue.status != DeliveryDead {
+        t.Fatalf("status = %s, want %s", queue.status, DeliveryDead)
+    }
+    if *requests != webhookMaxAttempts || queue.delivery.attempts != webhookMaxAttempts {
+        t.Errorf("requests = %d, attempts = %d, want %d", *requests, queue.delivery.attempts, webhookMaxAttempts)
+    }
+    if queue.lastCode != http.StatusServiceUnavailable {
+        t.Errorf("last status code = %d", queue.lastCode)
+    }
+    if len(queue.backoffs) != webhookMaxAttempts-1 || queue.backoffs[len(queue.backoffs)-1] != webhookBackoff(webhookMaxAttempts-1) {
+        t.Errorf("backoffs = %v", queue.backoffs)
+    }
+}
+
+func TestWebhookBackoff(t *testing.T) {
+    tests := []struct {
+        attempts int
+        want     time.Duration
+    }{
+        {1, 10 * time.Second},
+        {2, 20 * time.Second},
+        {4, 80 * time.Second},
+        {20, time.Hour},
+    }
+    for _, tt := range tests {
+        if got := webhookBackoff(tt.attempts); got != tt.want {
+            t.Errorf("webhookBackoff(%d) = %v, want %v", tt.attempts, got, tt.want)
+        }
+    }
+}
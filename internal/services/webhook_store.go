@@ -0,0 +1,277 @@
+package services
+
+import (
+    "context"
+    "crypto/rand"
+    "database/sql"
+    "encoding/hex"
+    "encoding/json"
+    "fmt"
+    "time"
+
+    "github.com/lib/pq"
+)
+
+// Webhook olay tipleri
+const (
+    WebhookStationStatusChanged = "station.status_changed"
+    WebhookStationAdded         = "station.added"
+    WebhookStationRemoved       = "station.removed"
+    WebhookReviewCreated        = "review.created"
+)
+
+var WebhookEventTypes = []string{
+    WebhookStationStatusChanged,
+    WebhookStationAdded,
+    WebhookStationRemoved,
+    WebhookReviewCreated,
+}
+
+// Teslimat durumları
+const (
+    DeliveryPending   = "pending"
+    DeliveryDelivered = "delivered"
+    DeliveryDead      = "dead"
+)
+
+type WebhookSubscription struct {
+    ID         int       `json:"id"`
+    URL        string    `json:"url"`
+    Secret     string    `json:"secret,omitempty"` // yalnızca oluşturulurken döner
+    EventTypes []string  `json:"event_types"`
+    CreatedAt  time.Time `json:"created_at"`
+}
+
+type WebhookDelivery struct {
+    ID             int64           `json:"id"`
+    SubscriptionID int             `json:"subscription_id"`
+    EventType      string          `json:"event_type"`
+    Payload        json.RawMessage `json:"payload"`
+    Status         string          `json:"status"`
+    Attempts       int             `json:"attempts"`
+    LastStatusCode *int            `json:"last_status_code,omitempty"`
+    LastError      *string         `json:"last_error,omitempty"`
+    NextAttemptAt  *time.Time      `json:"next_attempt_at,omitempty"`
+    CreatedAt      time.Time       `json:"created_at"`
+    DeliveredAt    *time.Time      `json:"delivered_at,omitempty"`
+}
+
+// WebhookStore, abonelikleri ve teslimat kayıtlarını Postgres'te tutar.
+// Teslimatlar önce "pending" olarak yazılır, böylece sunucu yeniden başlasa
+// da bekleyen denemeler kaybolmaz.
+type WebhookStore struct {
+    db *sql.DB
+}
+
+func NewWebhookStore(db *sql.DB) *WebhookStore {
+    return &WebhookStore{db: db}
+}
+
+func (s *WebhookStore) CreateSubscription(ctx context.Context, url string, eventTypes []string) (*WebhookSubscription, error) {
+    secret, err := randomHex(32)
+    if err != nil {
+        return nil, err
+    }
+
+    sub := &WebhookSubscription{URL: url, Secret: secret, EventTypes: eventTypes}
+    err = s.db.QueryRowContext(ctx, `
+        INSERT INTO webhook_subscriptions (url, secret, event_types, created_at)
+        VALUES ($1, $2, $3, NOW())
+        RETURNING id, created_at`,
+        url, secret, pq.Array(eventTypes)).Scan(&sub.ID, &sub.CreatedAt)
+    if err != nil {
+        return nil, err
+    }
+    return sub, nil
+}
+
+// ListSubscriptions, abonelikleri gizli anahtarları olmadan döndürür
+func (s *WebhookStore) ListSubscriptions(ctx context.Context) ([]WebhookSubscription, error) {
+    rows, err := s.db.QueryContext(ctx, `
+        SELECT id, url, event_types, created_at
+        FROM webhook_subscriptions
+        ORDER BY id`)
+    if err != nil {
+        return nil, err
+    }
+    defer rows.Close()
+
+    subs := []WebhookSubscription{}
+    for rows.Next() {
+        var sub WebhookSubscription
+        if err := rows.Scan(&sub.ID, &sub.URL, pq.Array(&sub.EventTypes), &sub.CreatedAt); err != nil {
+            return nil, err
+        }
+        subs = append(subs, sub)
+    }
+    return subs, rows.Err()
+}
+
+// DeleteSubscription, aboneliği ve teslimat kayıtlarını siler; bulunamazsa false döner
+func (s *WebhookStore) DeleteSubscription(ctx context.Context, id int) (bool, error) {
+    res, err := s.db.ExecContext(ctx, `DELETE FROM webhook_subscriptions WHERE id = $1`, id)
+    if err != nil {
+        return false, err
+    }
+    n, err := res.RowsAffected()
+    return n > 0, err
+}
+
+// enqueue, olayı ilgili tüm aboneliklere "pending" teslimat olarak yazar
+func (s *WebhookStore) enqueue(ctx context.Context, eventType string, payload []byte) (int64, error) {
+    res, err := s.db.ExecContext(ctx, `
+        INSERT INTO webhook_deliveries (subscription_id, event_type, payload, status, attempts, next_attempt_at, created_at)
+        SELECT id, $1::text, $2::jsonb, $3::text, 0, NOW(), NOW()
+        FROM webhook_subscriptions
+        WHERE $1::text = ANY(event_types)`,
+        eventType, string(payload), DeliveryPending)
+    if err != nil {
+        return 0, err
+    }
+    return res.RowsAffected()
+}
+
+// Teslim edilmek üzere alınmış bir kayıt
+type claimedDelivery struct {
+    id        int64
+    eventType string
+    payload   []byte
+    attempts  int
+    url       string
+    secret    string
+}
+
+// claimDue, zamanı gelmiş teslimatları kilitleyip lease süresi kadar ileri
+// erteler; böylece aynı kayıt iki kez gönderilmez
+func (s *WebhookStore) claimDue(ctx context.Context, limit int, lease time.Duration) ([]claimedDelivery, error) {
+    rows, err := s.db.QueryContext(ctx, `
+        UPDATE webhook_deliveries d
+        SET next_attempt_at = NOW() + make_interval(secs => $2)
+        FROM webhook_subscriptions s
+        WHERE d.subscription_id = s.id
+          AND d.id IN (
+              SELECT id FROM webhook_deliveries
+              WHERE status = $3 AND next_attempt_at <= NOW()
+              ORDER BY next_attempt_at
+              LIMIT $1
+              FOR UPDATE SKIP LOCKED)
+        RETURNING d.id, d.event_type, d.payload, d.attempts, s.url, s.secret`,
+        limit, lease.Seconds(), DeliveryPending)
+    if err != nil {
+        return nil, err
+    }
+    defer rows.Close()
+
+    var claimed []claimedDelivery
+    for rows.Next() {
+        var d claimedDelivery
+        if err := rows.Scan(&d.id, &d.eventType, &d.payload, &d.attempts, &d.url, &d.secret); err != nil {
+            return nil, err
+        }
+        claimed = append(claimed, d)
+    }
+    return claimed, rows.Err()
+}
+
+// recordAttempt, bir denemenin sonucunu yazar. nextAttempt sıfırsa ve
+// teslimat başarısızsa kayıt dead-letter listesine düşer.
+func (s *WebhookStore) recordAttempt(ctx context.Context, id int64, delivered bool, statusCode int, attemptErr string, nextAttempt time.Time) error {
+    status := DeliveryPending
+    switch {
+    case delivered:
+        status = DeliveryDelivered
+    case nextAttempt.IsZero():
+        status = DeliveryDead
+    }
+
+    var code sql.NullInt64
+    if statusCode > 0 {
+        code = sql.NullInt64{Int64: int64(statusCode), Valid: true}
+    }
+    var errText sql.NullString
+    if attemptErr != "" {
+        errText = sql.NullString{String: attemptErr, Valid: true}
+    }
+    var next sql.NullTime
+    if status == DeliveryPending {
+        next = sql.NullTime{Time: nextAttempt, Valid: true}
+    }
+
+    _, err := s.db.ExecContext(ctx, `
+        UPDATE webhook_deliveries
+        SET status = $2,
+            attempts = attempts + 1,
+            last_status_code = $3,
+            last_error = $4,
+            next_attempt_at = $5,
+            delivered_at = CASE WHEN $2 = 'delivered' THEN NOW() ELSE NULL END
+        WHERE id = $1`,
+        id, status, code, errText, next)
+    return err
+}
+
+// ListDeliveries, teslimat kayıtlarını yeniden eskiye döndürür. subscriptionID
+// 0 ise tüm abonelikler, status boşsa tüm durumlar listelenir.
+func (s *WebhookStore) ListDeliveries(ctx context.Context, subscriptionID int, status string, limit int) ([]WebhookDelivery, error) {
+    rows, err := s.db.QueryContext(ctx, `
+        SELECT id, subscription_id, event_type, payload, status, attempts,
+               last_status_code, last_error, next_attempt_at, created_at, delivered_at
+        FROM webhook_deliveries
+        WHERE ($1 = 0 OR subscription_id = $1) AND ($2 = '' OR status = $2)
+        ORDER BY id DESC
+        LIMIT $3`,
+        subscriptionID, status, limit)
+    if err != nil {
+        return nil, err
+    }
+    defer rows.Close()
+
+    deliveries := []WebhookDelivery{}
+    for rows.Next() {
+        var d WebhookDelivery
+        var code sql.NullInt64
+        var errText sql.NullString
+        var next, delivered sql.NullTime
+        if err := rows.Scan(&d.ID, &d.SubscriptionID, &d.EventType, &d.Payload, &d.Status, &d.Attempts,
+            &code, &errText, &next, &d.CreatedAt, &delivered); err != nil {
+            return nil, err
+        }
+        if code.Valid {
+            v := int(code.Int64)
+            d.LastStatusCode = &v
+        }
+        if errText.Valid {
+            d.LastError = &errText.String
+        }
+        if next.Valid {
+            d.NextAttemptAt = &next.Time
+        }
+        if delivered.Valid {
+            d.DeliveredAt = &delivered.Time
+        }
+        deliveries = append(deliveries, d)
+    }
+    return deliveries, rows.Err()
+}
+
+// RetryDelivery, dead-letter listesindeki bir teslimatı yeniden kuyruğa alır
+func (s *WebhookStore) RetryDelivery(ctx context.Context, id int64) (bool, error) {
+    res, err := s.db.ExecContext(ctx, `
+        UPDATE webhook_deliveries
+        SET status = $2, attempts = 0, next_attempt_at = NOW()
+        WHERE id = $1 AND status = $3`,
+        id, DeliveryPending, DeliveryDead)
+    if err != nil {
+        return false, err
+    }
+    n, err := res.RowsAffected()
+    return n > 0, err
+}
+
+func randomHex(n int) (string, error) {
+    b := make([]byte, n)
+    if _, err := rand.Read(b); err != nil {
+        return "", fmt.Errorf("rastgele değer üretilemedi: %v", err)
+    }
+    return hex.EncodeToString(b), nil
+}
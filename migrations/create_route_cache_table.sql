@@ -0,0 +1,7 @@
+CREATE TABLE IF NOT EXISTS route_cache (
+    cache_key TEXT PRIMARY KEY,
+    payload JSONB NOT NULL,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL
+);
+
+CREATE INDEX idx_route_cache_expires_at ON route_cache(expires_at);
@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS station_availability (
+    id BIGSERIAL PRIMARY KEY,
+    station_id VARCHAR(255) NOT NULL,
+    ac_available INTEGER NOT NULL,
+    dc_available INTEGER NOT NULL,
+    error_count INTEGER NOT NULL,
+    unavailable_count INTEGER NOT NULL,
+    total_connectors INTEGER NOT NULL,
+    recorded_at TIMESTAMP WITH TIME ZONE NOT NULL
+);
+
+CREATE INDEX idx_station_availability_station_time ON station_availability(station_id, recorded_at);
+CREATE INDEX idx_station_availability_recorded_at ON station_availability(recorded_at);
@@ -0,0 +1,10 @@
+CREATE TABLE IF NOT EXISTS station_changes (
+    id BIGSERIAL PRIMARY KEY,
+    station_id VARCHAR(255) NOT NULL,
+    change_type VARCHAR(32) NOT NULL,
+    details JSONB NOT NULL,
+    detected_at TIMESTAMP WITH TIME ZONE NOT NULL
+);
+
+CREATE INDEX idx_station_changes_detected_at ON station_changes(detected_at);
+CREATE INDEX idx_station_changes_station_id ON station_changes(station_id);
@@ -0,0 +1,3 @@
+-- Yorumlardaki eski, ön eksiz Trugo istasyon ID'lerini ad alanlı anahtarlara taşır.
+-- Tek seferlik veri taşımasıdır; sunucu açılışında çalışmaz, dağıtımda bir kez uygulanır.
+UPDATE reviews SET station_id = 'trugo:' || station_id WHERE station_id ~ '^[0-9]+$';